@@ -10,26 +10,56 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/config"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/gitdiff"
 	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
 	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/sarif"
 	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/sdkman"
 	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
 )
 
 const (
-	flagCompile   = "compile"
-	flagFailNever = "fail-never"
-	flagJavaOpts  = "javaOpts"
-	pathExclude   = "/spotbugs/exclude.xml"
-	pathInclude   = "/spotbugs/include.xml"
-	pathJarsList  = "/tmp/jars.list"
-	pathOutput    = "/tmp/SpotBugs.xml"
-	pathSpotBugs  = "/spotbugs/dist"
-	pluginList    = "/fsb/lib/findsecbugs-plugin.jar"
+	// flagArtifactDir is defined by the run command of the common library.
+	flagArtifactDir      = "artifact-dir"
+	flagArtifacts        = "artifacts"
+	flagBaseline         = "baseline"
+	flagBaselineMode     = "baselineMode"
+	flagCompile          = "compile"
+	flagConcurrency      = "concurrency"
+	flagConfigFile       = "configFile"
+	flagDiffBase         = "diffBase"
+	flagDiffLinesOnly    = "diffLinesOnly"
+	flagDisableDetectors = "disableDetectors"
+	flagEffort           = "effort"
+	flagEnableDetectors  = "enableDetectors"
+	flagExcludeFilters   = "excludeFilters"
+	flagExcludeTestPaths = "excludeTestPaths"
+	flagExtraArgs        = "extraArgs"
+	flagFailNever        = "fail-never"
+	flagIncludeFilters   = "includeFilters"
+	flagIncludePaths     = "includePaths"
+	flagIncludeTests     = "includeTestClasses"
+	flagJavaOpts         = "javaOpts"
+	flagKeepArtifacts    = "keepArtifacts"
+	flagMaxRank          = "maxRank"
+	flagOutputFormat     = "outputFormat"
+	flagPriority         = "priority"
+	flagSuppressionMode  = "suppressionMode"
+	flagWorkDir          = "workDir"
+	fileSARIF            = "gl-sast-report.sarif"
+	outputFormatGitLab   = "gitlab"
+	outputFormatSARIF    = "sarif"
+	pathExclude          = "/spotbugs/exclude.xml"
+	pathInclude          = "/spotbugs/include.xml"
+	pathSpotBugs         = "/spotbugs/dist"
+	pluginList           = "/fsb/lib/findsecbugs-plugin.jar"
 )
 
 func analyzeFlags() []cli.Flag {
@@ -38,6 +68,30 @@ func analyzeFlags() []cli.Flag {
 		home = "/"
 	}
 	return []cli.Flag{
+		cli.StringFlag{
+			Name:   flagArtifacts,
+			Usage:  "Analyze the prebuilt JAR, WAR and EAR files matching these comma separated glob patterns, relative to the project directory, instead of building the source code.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_ARTIFACTS",
+		},
+		cli.StringFlag{
+			Name:   flagBaseline,
+			Usage:  "Define path to the baseline file, relative to the project directory. Findings present in the baseline aren't reported as new.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_BASELINE",
+		},
+		cli.StringFlag{
+			Name:   flagBaselineMode,
+			Usage:  "Define what happens to findings present in the baseline. Valid values are drop and suppress.",
+			Value:  baselineModeDrop,
+			EnvVar: "SPOTBUGS_BASELINE_MODE",
+		},
+		cli.StringFlag{
+			Name:   flagSuppressionMode,
+			Usage:  "Define what happens to findings suppressed by a spotbugs-ignore comment. Valid values are drop and suppress.",
+			Value:  suppressionModeDrop,
+			EnvVar: "SPOTBUGS_SUPPRESSION_MODE",
+		},
 		cli.StringFlag{
 			Name:   project.FlagAntPath,
 			Usage:  "Define path to ant executable.",
@@ -50,22 +104,157 @@ func analyzeFlags() []cli.Flag {
 			Value:  "",
 			EnvVar: "ANT_HOME",
 		},
+		cli.StringFlag{
+			Name:   project.FlagBazelPath,
+			Usage:  "Define path to bazel executable.",
+			Value:  "bazel",
+			EnvVar: "BAZEL_PATH",
+		},
+		cli.StringFlag{
+			Name:   project.FlagBuildCommand,
+			Usage:  "Define a custom shell command building the project, instead of detecting the build tools of its directories.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_BUILD_COMMAND",
+		},
+		cli.StringFlag{
+			Name:   project.FlagBuildDir,
+			Usage:  "Define the directory where the custom build command runs, relative to the project directory.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_BUILD_DIR",
+		},
+		cli.StringFlag{
+			Name:   project.FlagClassDirs,
+			Usage:  "Define the comma separated globs of the class directories and jars written by the custom build command, relative to the build directory.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_CLASS_DIRS",
+		},
+		cli.StringFlag{
+			Name:   project.FlagClasspathFile,
+			Usage:  "Define the file listing the classpath of the custom build command, relative to the build directory.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_CLASSPATH_FILE",
+		},
 		cli.BoolTFlag{
 			Name:   flagCompile,
 			Usage:  "Compile source code. It's not needed if the code is already compiled.",
 			EnvVar: "COMPILE",
 		},
+		cli.IntFlag{
+			Name:   flagConcurrency,
+			Usage:  "Define how many projects or artifacts are analyzed by SpotBugs in parallel.",
+			Value:  1,
+			EnvVar: "SPOTBUGS_CONCURRENCY",
+		},
+		cli.StringFlag{
+			Name:   flagConfigFile,
+			Usage:  "Define path to the configuration file pinning builders, build commands and Java versions of directories, relative to the project directory.",
+			Value:  config.DefaultFilename,
+			EnvVar: "SPOTBUGS_CONFIG_FILE",
+		},
+		cli.StringFlag{
+			Name:   flagDiffBase,
+			Usage:  "Only analyze the source files changed since this git reference (branch, tag or commit SHA).",
+			Value:  "",
+			EnvVar: "SPOTBUGS_DIFF_BASE",
+		},
+		cli.BoolFlag{
+			Name:   flagDiffLinesOnly,
+			Usage:  "Only report findings on changed lines, when a diff base is defined.",
+			EnvVar: "SPOTBUGS_DIFF_LINES_ONLY",
+		},
+		cli.StringFlag{
+			Name:   flagDisableDetectors,
+			Usage:  "Comma separated list of SpotBugs detectors to disable.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_DISABLE_DETECTORS",
+		},
+		cli.StringFlag{
+			Name:   flagEffort,
+			Usage:  "Define the SpotBugs effort level, trading precision for speed. Valid values are min, less, default, more and max.",
+			Value:  effortDefault,
+			EnvVar: "SPOTBUGS_EFFORT",
+		},
+		cli.StringFlag{
+			Name:   flagEnableDetectors,
+			Usage:  "Comma separated list of SpotBugs detectors to enable, including the ones disabled by default.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_ENABLE_DETECTORS",
+		},
+		cli.StringFlag{
+			Name:   flagExcludeFilters,
+			Usage:  "Comma separated list of SpotBugs filter files, relative to the project directory, matching bugs that aren't reported.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_EXCLUDE_FILTERS",
+		},
+		cli.StringFlag{
+			Name:   flagExcludeTestPaths,
+			Usage:  "Comma separated list of test source directories not analyzed, matching from any directory. Cleared by default when analyzing test classes.",
+			Value:  defaultTestPaths,
+			EnvVar: "SPOTBUGS_EXCLUDE_TEST_PATHS",
+		},
+		cli.StringFlag{
+			Name:   flagExtraArgs,
+			Usage:  "Define extra arguments passed to SpotBugs, separated by spaces.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_EXTRA_ARGS",
+		},
 		cli.BoolFlag{
 			Name:   flagFailNever,
 			Usage:  "Ignore compilation failures, attempt scan anyway.",
 			EnvVar: "FAIL_NEVER",
 		},
+		cli.StringFlag{
+			Name:   flagIncludeFilters,
+			Usage:  "Comma separated list of SpotBugs filter files, relative to the project directory, matching bugs reported in addition to the security ones.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_INCLUDE_FILTERS",
+		},
+		cli.StringFlag{
+			Name:   flagIncludePaths,
+			Usage:  "Comma separated list of paths, relative to the repository, of the only source files analyzed.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_INCLUDE_PATHS",
+		},
+		cli.BoolFlag{
+			Name:   flagIncludeTests,
+			Usage:  "Analyze the compiled test classes along with the main classes.",
+			EnvVar: "SPOTBUGS_INCLUDE_TEST_CLASSES",
+		},
 		cli.StringFlag{
 			Name:   flagJavaOpts,
 			Usage:  "Define JAVA_OPTS.",
 			Value:  "-Xmx1900M",
 			EnvVar: "JAVA_OPTS",
 		},
+		cli.BoolFlag{
+			Name:   flagKeepArtifacts,
+			Usage:  "Keep the jar list, SpotBugs report and logs of each project after the analysis.",
+			EnvVar: "SPOTBUGS_KEEP_ARTIFACTS",
+		},
+		cli.IntFlag{
+			Name:   flagMaxRank,
+			Usage:  "Only report bugs of this rank or scarier, from 1 (scariest) to 20 (all bugs).",
+			Value:  maxRank,
+			EnvVar: "SPOTBUGS_MAX_RANK",
+		},
+		cli.StringFlag{
+			Name:   flagOutputFormat,
+			Usage:  "Define the report format. Valid values are gitlab and sarif, which also writes a SARIF report next to the GitLab one.",
+			Value:  outputFormatGitLab,
+			EnvVar: "SPOTBUGS_OUTPUT_FORMAT",
+		},
+		cli.StringFlag{
+			Name:   flagPriority,
+			Usage:  "Only report bugs of this priority or higher. Valid values are low, medium and high.",
+			Value:  priorityLow,
+			EnvVar: "SPOTBUGS_PRIORITY",
+		},
+		cli.StringFlag{
+			Name:   flagWorkDir,
+			Usage:  "Define the directory where the temporary workspace of each project is created.",
+			Value:  "",
+			EnvVar: "SPOTBUGS_WORK_DIR",
+		},
 		cli.StringFlag{
 			Name:   project.FlagGradlePath,
 			Usage:  "Define path to gradle executable.",
@@ -104,7 +293,7 @@ func analyzeFlags() []cli.Flag {
 		},
 		cli.StringFlag{
 			Name:   sdkman.FlagJavaVersion,
-			Usage:  "Define which major Java version to use.",
+			Usage:  "Define which major Java version (8, 11, 17, 21) or exact SDKMAN Java identifier to use, instead of the one detected from the build files.",
 			Value:  "8",
 			EnvVar: "SAST_JAVA_VERSION",
 		},
@@ -120,192 +309,450 @@ func analyzeFlags() []cli.Flag {
 			Value:  "11.0.6.hs-adpt",
 			EnvVar: "JAVA_11_VERSION",
 		},
+		cli.StringFlag{
+			Name:   sdkman.FlagJava17Version,
+			Usage:  "Define which version of Java 17 to use.",
+			Value:  "17.0.9-tem",
+			EnvVar: "JAVA_17_VERSION",
+		},
+		cli.StringFlag{
+			Name:   sdkman.FlagJava21Version,
+			Usage:  "Define which version of Java 21 to use.",
+			Value:  "21.0.1-tem",
+			EnvVar: "JAVA_21_VERSION",
+		},
 		cli.StringFlag{
 			Name:   sdkman.FlagSdkmanDir,
 			Usage:  "Define path to sdkman home directory.",
 			Value:  "/usr/local/sdkman",
 			EnvVar: "SDKMAN_DIR",
 		},
+		cli.StringFlag{
+			Name:   sdkman.FlagJdkDir,
+			Usage:  "Define a directory of unpacked JDKs or JDK archives to use instead of installing Java with SDKMAN.",
+			EnvVar: "SPOTBUGS_JDK_DIR",
+		},
 	}
 }
 
 // Set compile function as package-level var to make mocking easier
 var compileProj = compile
 
+// Set analyze function as package-level var to make mocking easier
+var analyzeProj = analyzeProject
+
 // analyze compiles (if asked) and analyzes every buildable project found in the given directory
 func analyze(c *cli.Context, repositoryPath string) (io.ReadCloser, error) {
-	sdkman.SetupSystemJava(c)
+	if err := validateOutputFormat(c); err != nil {
+		return nil, err
+	}
 
-	projects, err := project.FindProjects(repositoryPath, false)
+	if err := validateBaselineMode(c); err != nil {
+		return nil, err
+	}
+
+	if err := validateSuppressionMode(c); err != nil {
+		return nil, err
+	}
+
+	if err := validateSpotBugsOptions(c); err != nil {
+		return nil, err
+	}
+
+	if err := resolveFilters(c, repositoryPath); err != nil {
+		return nil, err
+	}
+	log.Infof("Running SpotBugs with %s.\n", formatSettings(scannerSettings(c)))
+
+	changes, err := computeChanges(c, repositoryPath)
 	if err != nil {
 		return nil, err
 	}
 
+	finalReport, err := findBugInstances(c, repositoryPath, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only keep findings in changed code.
+	if changes != nil {
+		finalReport.Instances = filterChanges(c, changes, finalReport.Instances)
+	}
+
+	// Remove or suppress already triaged findings.
+	if c.String(flagBaseline) != "" {
+		finalReport.Instances, err = applyBaseline(c, repositoryPath, finalReport.Instances)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.String(flagOutputFormat) == outputFormatSARIF {
+		if err := writeSARIF(c, repositoryPath, finalReport); err != nil {
+			return nil, err
+		}
+	}
+
+	return marshallToXML(c, finalReport)
+}
+
+// findBugInstances compiles (if asked) and analyzes every buildable project found in the given directory,
+// and returns the bug instances sorted by file name. When changes are given, only the projects and packages
+// containing changed source files are analyzed.
+func findBugInstances(c *cli.Context, repositoryPath string, changes *gitdiff.Changes) (instance.Instances, error) {
+	if err := sdkman.SetupSystemJava(c); err != nil {
+		return instance.Instances{}, err
+	}
+
+	// Excluded paths aren't analyzed, and the findings they contain are dropped.
+	filter, err := pathFilter(c)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+
+	if c.String(flagArtifacts) != "" {
+		finalReport, err := analyzeArtifacts(c, repositoryPath)
+		if err != nil {
+			return instance.Instances{}, err
+		}
+
+		finalReport.Instances = filterPaths(filter, finalReport.Instances)
+		instance.By(fileName).Sort(finalReport.Instances)
+		return finalReport, nil
+	}
+
+	projects, err := findProjects(c, repositoryPath, filter)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+
+	if changes != nil {
+		projects = restrictToChanges(repositoryPath, projects, changes)
+	}
+
 	log.Infof("Found %d analyzable projects.\n", len(projects))
 
+	// Select the JDK of each project.
+	if err := selectToolchains(c, projects); err != nil {
+		return instance.Instances{}, err
+	}
+
 	// Compile source code if needed.
 	if c.BoolT(flagCompile) {
 		if err := compileProj(c, projects, c.Bool(flagFailNever)); err != nil {
-			return nil, err
+			return instance.Instances{}, err
 		}
 	}
 
+	// Run SpotBugs on projects.
+	finalReport, err := analyzeProjects(c, repositoryPath, projects)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+
+	// Drop the findings in excluded paths, like test sources sharing packages with the analyzed ones.
+	finalReport.Instances = filterPaths(filter, finalReport.Instances)
+
+	// Sort reports by filename for repeatable comparison in tests.
+	instance.By(fileName).Sort(finalReport.Instances)
+
+	return finalReport, nil
+}
+
+// analyzeProjects runs SpotBugs on the projects using a pool of workers.
+func analyzeProjects(c *cli.Context, repositoryPath string, projects []project.Project) (instance.Instances, error) {
+	return analyzeConcurrently(c, len(projects), func(i int) (instance.Instances, error) {
+		return analyzeAndCorrectProject(c, repositoryPath, projects[i])
+	})
+}
+
+// analyzeConcurrently runs the analysis of count items using a pool of workers, and merges their bug instances
+// in the order of the items so that the result doesn't depend on scheduling.
+func analyzeConcurrently(c *cli.Context, count int, analyze func(i int) (instance.Instances, error)) (instance.Instances, error) {
+	concurrency := c.Int(flagConcurrency)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > count {
+		concurrency = count
+	}
+
+	results := make([]instance.Instances, count)
+	errs := make([]error, count)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = analyze(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
 	// Create a new Instances struct, it will receive the content of each fsb XML report.
 	finalReport := instance.Instances{}
-
-	// Run SpotBugs on projects.
-	for _, p := range projects {
-		bugInstances, err := analyzeProject(c, p)
-		if err != nil {
+	for i := 0; i < count; i++ {
+		if errs[i] != nil {
 			// Fail if even one report fails to be processed, to avoid false negatives.
-			return nil, err
+			return instance.Instances{}, errs[i]
 		}
 
-		corrected, err := correctPath(repositoryPath, p, bugInstances)
-		if err != nil {
-			// Fail if even one report fails to be processed, to avoid false negatives.
-			return nil, err
+		finalReport.Instances = append(finalReport.Instances, results[i].Instances...)
+		finalReport.AddBugPatterns(results[i].BugPatterns)
+	}
+
+	// Overlapping projects, or archives containing the same classes, can report the same bug instances.
+	finalReport.Instances = deduplicate(finalReport.Instances)
+
+	return finalReport, nil
+}
+
+// deduplicate returns the bug instances without the ones reported more than once at the same location,
+// keeping the first occurrence.
+func deduplicate(bugInstances []instance.Instance) []instance.Instance {
+	seen := make(map[string]bool, len(bugInstances))
+	result := make([]instance.Instance, 0, len(bugInstances))
+	for _, bug := range bugInstances {
+		key := fmt.Sprintf("%s:%s:%s:%d:%d",
+			bug.Type, bug.InstanceHash, bug.SourceLine.SourcePath, bug.SourceLine.Start, bug.SourceLine.End)
+		if seen[key] {
+			continue
 		}
+		seen[key] = true
 
-		finalReport.Instances = append(finalReport.Instances, corrected...)
+		result = append(result, bug)
 	}
 
-	// Sort reports by filename for repeatable comparison in tests.
-	instance.By(fileName).Sort(finalReport.Instances)
+	return result
+}
 
-	return marshallToXML(c, finalReport)
+// analyzeAndCorrectProject runs SpotBugs on a project and makes the reported paths relative to the repository.
+func analyzeAndCorrectProject(c *cli.Context, repositoryPath string, p project.Project) (instance.Instances, error) {
+	if !p.HasSourceFiles() {
+		// Typically the root of a Maven reactor, whose modules are analyzed separately.
+		log.Infof("Skipping analysis of %s, it has no source file of its own.\n", p.Path)
+		return instance.Instances{}, nil
+	}
+
+	report, err := analyzeProj(c, p)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+
+	report.Instances, err = correctPath(repositoryPath, p, report.Instances)
+	if err != nil {
+		return instance.Instances{}, err
+	}
 
+	// Hash the source lines of each issue, used to track issues across changes.
+	instance.HashSources(repositoryPath, report.Instances)
+
+	// Honor the suppression comments of the source files.
+	report.Instances = suppressInSource(c, repositoryPath, report.Instances)
+
+	return report, nil
 }
 
 // analyzeProject runs SpotBugs of a project directory
-func analyzeProject(c *cli.Context, p project.Project) ([]instance.Instance, error) {
+func analyzeProject(c *cli.Context, p project.Project) (instance.Instances, error) {
+	// Each project gets its own workspace so that concurrent analyses don't overwrite each other's files.
+	ws, err := newWorkspace(c, p.Path)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+	defer utils.WithWarning(fmt.Sprintf("Couldn't remove workspace %s", ws.Path), ws.Close)
+
 	// Build a file containing the list of JARs libraries used by the project
-	if err := buildJarsList(c, p); err != nil {
-		return nil, err
+	if err := buildJarsList(c, &p, ws); err != nil {
+		return instance.Instances{}, err
 	}
 
-	params, err := buildSpotBugsParams(c, p)
-	// log.Infof(strings.Join(params, " "))
+	targets, err := classTargets(c, &p)
 	if err != nil {
-		log.Errorf("Error: Couldn't build the spotbugs command parameter list: %v\n", err)
-		return nil, err
+		return instance.Instances{}, err
+	}
+
+	return runSpotBugs(c, p.Path, ws, p.Packages(), targets)
+}
+
+// runSpotBugs runs SpotBugs in a directory on the packages of the targets, or all their classes if no package is
+// given, and reads the bug instances of its report.
+func runSpotBugs(c *cli.Context, dir string, ws *workspace, packages []string, targets []string) (instance.Instances, error) {
+	// SpotBugs can't read class files compiled for a newer Java than the one running it.
+	javaPath, err := spotBugsJavaPath(c, targets)
+	if err != nil {
+		log.Errorf("Error: Couldn't select the Java running SpotBugs for %s: %s\n", dir, err.Error())
+		return instance.Instances{}, err
 	}
 
-	// Run the SpotBugs command line tool on the project
+	if err := writeFilters(c, ws); err != nil {
+		log.Errorf("Error: Couldn't write the SpotBugs filters for %s: %s\n", dir, err.Error())
+		return instance.Instances{}, err
+	}
+
+	// Run the SpotBugs command line tool
 	cmd := utils.SetupCmdNoStd(
-		p.Path,
+		dir,
 		exec.Command(
-			sdkman.JavaPath(c),
-			params...))
+			javaPath,
+			spotBugsArgs(c, ws, packages, targets)...))
 
+	startTime := time.Now()
 	output, err := cmd.CombinedOutput()
+	utils.WithWarning(
+		fmt.Sprintf("Couldn't write SpotBugs logs to %s", ws.Log()),
+		func() error { return ioutil.WriteFile(ws.Log(), output, 0644) })
 	if err != nil {
 		log.Errorf(
 			"Error: SpotBugs analysis failed for %s: %s\n",
-			p.Path,
+			dir,
 			err.Error())
-		return nil, err
+		return instance.Instances{}, err
 	}
 	log.Debugf("%s\n%s", cmd.String(), output)
 
 	if strings.Contains(string(output), "No classfiles specified; output will have no warnings") {
 		// No classes were found, this could mean the build process failed.
-		log.Warnf("SpotBugs didn't find any class file to analyze in %s !\n", p.Path)
+		log.Warnf("SpotBugs didn't find any class file to analyze in %s !\n", dir)
 	} else {
-		log.Infof("SpotBugs analysis succeeded for %s!\n", p.Path)
+		log.Infof("SpotBugs analysis succeeded for %s!\n", dir)
+	}
+
+	// Make sure we don't read a report that SpotBugs didn't write.
+	pathOutput := ws.Output()
+	if err := checkFreshReport(pathOutput, startTime); err != nil {
+		log.Errorf("Error: Invalid XML report for %s: %s\n", dir, err.Error())
+		return instance.Instances{}, err
 	}
 
 	// read the XML report into a struct
 	reportFile, err := os.Open(pathOutput)
 	if err != nil {
 		log.Errorf("Error: Unable to open XML report %s: %s\n", pathOutput, err.Error())
-		return nil, err
+		return instance.Instances{}, err
 	}
 	defer utils.WithWarning(fmt.Sprintf("Couldn't close %s", pathOutput), reportFile.Close)
 
-	bugInstances := &instance.Instances{}
+	bugInstances := instance.Instances{}
 	err = xml.NewDecoder(reportFile).Decode(&bugInstances)
 	if err != nil {
 		log.Errorf("Error: Unable to parse XML report %s: %s\n", pathOutput, err.Error())
+		return instance.Instances{}, err
+	}
+
+	return bugInstances, nil
+}
+
+// classTargets returns the class directories and jars of the project analyzed by SpotBugs
+func classTargets(c *cli.Context, p *project.Project) ([]string, error) {
+	// Gather the class directories reported by the builder. They contain the generated .class files.
+	targets, err := p.ClassDirs(c, c.Bool(flagIncludeTests))
+	if err != nil {
+		log.Errorf("Error: Couldn't get a list of class directories in %s: %v\n", p.Path, err)
 		return nil, err
 	}
+	if len(targets) == 0 {
+		// Let SpotBugs search the whole project for class files.
+		log.Warnf("No class directory found in %s, analyzing all its class files.\n", p.Path)
+		targets = []string{p.Path}
+	}
 
-	return bugInstances.Instances, nil
+	return targets, nil
 }
 
-// buildSpotBugsParams build the arguments for the SpotBugs command
-func buildSpotBugsParams(c *cli.Context, p project.Project) ([]string, error) {
+// spotBugsArgs returns the arguments of the SpotBugs command analyzing the packages of the targets, or all their
+// classes if no package is given.
+func spotBugsArgs(c *cli.Context, ws *workspace, packages []string, targets []string) []string {
 	// build the list of packages to analyze
-	packages := p.Packages()
 	packageList := make([]string, len(packages))
 	for i, p := range packages {
 		packageList[i] = p + ".*"
 	}
 
-	// Gather target directories. They contain the generated .class files.
-	targets, err := getTargetDirs(p)
-	if err != nil {
-		log.Errorf("Error: Couldn't get a list of target directories in %s: %v\n", p.Path, err)
-		return nil, err
-	}
-
 	args := []string{
 		"-cp", pathSpotBugs + "/lib/*",
 		c.String(flagJavaOpts),
 		"-jar", pathSpotBugs + "/lib/spotbugs.jar",
 		"-pluginList", pluginList,
-		"-exclude", pathExclude,
-		"-include", pathInclude,
-		"-onlyAnalyze", strings.Join(packageList, ","), // Don't analyze packages not in the source files.
+		"-exclude", ws.ExcludeFilter(),
+		"-include", ws.IncludeFilter(),
+	}
+	if len(packageList) > 0 {
+		args = append(args, "-onlyAnalyze", strings.Join(packageList, ",")) // Don't analyze packages not in the source files.
+	}
+	args = append(args,
 		"-quiet",
-		"-effort:max", // Max precision and more vulnerabilities found.
-		"-low",        // Report all bugs.
-		"-noClassOk",  // Don't fail on absence of .class files (we handle this case).
+		"-noClassOk", // Don't fail on absence of .class files (we handle this case).
 		"-xml:withMessages",
-		"-auxclasspathFromFile", pathJarsList,
-		"-output", pathOutput,
+		"-auxclasspathFromFile", ws.JarsList(),
+		"-output", ws.Output(),
+	)
+	args = append(args, spotBugsOptionArgs(c)...) // Max precision and all bugs by default.
+	return append(args, targets...)
+}
+
+// Set classpath resolution function as package-level var to make mocking easier
+var resolveClasspath = func(c *cli.Context, p *project.Project, outputFile string) ([]string, error) {
+	return p.Classpath(c, outputFile)
+}
+
+// buildJarsList writes the auxiliary classpath of the project into the jar list of the workspace.
+// It uses the dependency classpath resolved by the build tool, so that only the library versions used by
+// the project are listed, or the jars of the dependency caches of the build tool if it can't be resolved.
+func buildJarsList(c *cli.Context, p *project.Project, ws *workspace) error {
+	jars, err := resolveClasspath(c, p, ws.Classpath())
+	if err == nil {
+		log.Infof("Resolved %d classpath entries for %s.\n", len(jars), p.Path)
+	} else {
+		log.Warnf("Couldn't resolve the classpath of %s, using the jars of the dependency caches: %s\n",
+			p.Path, err.Error())
+
+		if jars, err = p.CachedJars(c); err != nil {
+			return err
+		}
 	}
-	args = append(args, p.Path)
-	return append(args, targets...), nil
 
+	return writeJarsList(ws, jars)
 }
 
-// buildJarsList writes a list of .jar files used by the project into a file.
-func buildJarsList(c *cli.Context, p project.Project) error {
-	f, err := os.OpenFile(pathJarsList, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+// writeJarsList writes the jars into the jar list of the workspace, one per line.
+func writeJarsList(ws *workspace, jars []string) error {
+	f, err := os.OpenFile(ws.JarsList(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
-	defer utils.WithWarning(fmt.Sprintf("Warning: Couldn't close jar list file %s", pathJarsList), f.Close)
+	defer utils.WithWarning(fmt.Sprintf("Warning: Couldn't close jar list file %s", ws.JarsList()), f.Close)
 
-	if p.UsesMaven() {
-		// Add .jar files from the local Maven repository for Maven projects.
-		localRepo := c.String(project.FlagMavenRepoPath)
-		if []rune(localRepo)[0] != '/' {
-			// This path is relative to the project path, get a full path.
-			localRepo = filepath.Join(p.Path, localRepo)
+	for _, jar := range jars {
+		if _, err := fmt.Fprintln(f, jar); err != nil {
+			return err
 		}
-
-		return filepath.Walk(localRepo, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() && filepath.Ext(info.Name()) == ".jar" {
-				if _, err := fmt.Fprintln(f, path); err != nil {
-					return err
-				}
-			}
-			return nil
-		})
 	}
 
 	return nil
 }
 
 func compile(c *cli.Context, projects []project.Project, failNever bool) error {
+	// Modules of a Maven reactor are compiled by building its root project once.
+	built := make(map[string]bool)
 
 	// Use the builder defined in the projects to compile them
-	for _, p := range projects {
+	for _, proj := range projects {
+		p := proj.BuiltBy()
+		if built[p.Path] {
+			continue
+		}
+		built[p.Path] = true
+
 		if err := p.Build(c); err != nil {
 			if !failNever {
 				return err
@@ -319,6 +766,48 @@ func compile(c *cli.Context, projects []project.Project, failNever bool) error {
 	return nil
 }
 
+// findProjects returns the project built by the custom build command if there is one, or the projects found in
+// the repository with the overrides of the configuration file, without the paths excluded by the filter.
+func findProjects(c *cli.Context, repositoryPath string, filter *project.PathFilter) ([]project.Project, error) {
+	if c.String(project.FlagBuildCommand) != "" {
+		p, err := project.NewCommandProject(c, repositoryPath, filter)
+		if err != nil {
+			log.Errorf("Error: Invalid custom build command: %s\n", err.Error())
+			return nil, err
+		}
+
+		log.Infof("Using custom build command for %s directory\n", p.Path)
+		return []project.Project{*p}, nil
+	}
+
+	cfg, err := loadConfig(c, repositoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return project.FindProjectsWithFilter(repositoryPath, false, cfg.Overrides(), filter)
+}
+
+// loadConfig reads the configuration file, relative to the repository unless its path is absolute.
+// A missing configuration file is ignored.
+func loadConfig(c *cli.Context, repositoryPath string) (*config.Config, error) {
+	filename := c.String(flagConfigFile)
+	if filename == "" {
+		return &config.Config{}, nil
+	}
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(repositoryPath, filename)
+	}
+
+	cfg, err := config.Load(filename)
+	if err != nil {
+		log.Errorf("Error: %s\n", err.Error())
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
 // correctPath corrects the SourceLine.SourcePath field so that it is relative to the repository root
 // so that users can immediately find the file without needing to search for it themselves.
 func correctPath(repositoryPath string, p project.Project, bugInstances []instance.Instance) ([]instance.Instance, error) {
@@ -378,23 +867,36 @@ func fileName(b1, b2 *instance.Instance) bool {
 	return b1.ShortMessage < b2.ShortMessage
 }
 
-// getTargetDirs returns the list of directories named "target" in the project directory
-func getTargetDirs(p project.Project) ([]string, error) {
-	var targets []string
-	err := filepath.Walk(p.Path, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() && info.Name() == "target" {
-			targets = append(targets, path)
-		}
+// validateOutputFormat returns an error if the output format isn't supported.
+func validateOutputFormat(c *cli.Context) error {
+	switch c.String(flagOutputFormat) {
+	case "", outputFormatGitLab, outputFormatSARIF:
 		return nil
-	})
+	default:
+		return fmt.Errorf(
+			"output format %s is not supported. Valid values are %s, %s",
+			c.String(flagOutputFormat), outputFormatGitLab, outputFormatSARIF)
+	}
+}
+
+// writeSARIF writes the bug instances as a SARIF file in the artifact directory, or in the repository
+// when no artifact directory is defined.
+func writeSARIF(c *cli.Context, repositoryPath string, instances instance.Instances) error {
+	dir := c.String(flagArtifactDir)
+	if dir == "" {
+		dir = repositoryPath
+	}
+	path := filepath.Join(dir, fileSARIF)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
-		return nil, err
+		log.Errorf("Error: Unable to create SARIF report %s: %s\n", path, err.Error())
+		return err
 	}
+	defer utils.WithWarning(fmt.Sprintf("Couldn't close %s", path), f.Close)
 
-	return targets, nil
+	log.Infof("Writing SARIF report to %s\n", path)
+	return sarif.NewLog(instances.Instances, scannerSettings(c)).Write(f)
 }
 
 func marshallToXML(c *cli.Context, instances instance.Instances) (io.ReadCloser, error) {
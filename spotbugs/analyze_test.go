@@ -7,13 +7,16 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli"
 	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/command"
 	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/issue"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
 	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/sdkman"
 )
 
 func mockMatch(path string, info os.FileInfo) (bool, error) {
@@ -41,6 +44,8 @@ func TestAnalyze(t *testing.T) {
 
 	app := *newMockApp()
 	set := flag.NewFlagSet("javaPath", 0)
+	// A custom Java path doesn't install Java with SDKMAN.
+	set.String(sdkman.FlagJavaPath, "/usr/bin/java", "java path")
 	c := *cli.NewContext(&app, set, nil)
 
 	want := ioutil.NopCloser(bytes.NewReader(([]byte("<Instances></Instances>"))))
@@ -58,6 +63,8 @@ func TestAnalyzeNoCompile(t *testing.T) {
 
 	app := *newMockApp()
 	set := flag.NewFlagSet("javaPath", 0)
+	// A custom Java path doesn't install Java with SDKMAN.
+	set.String(sdkman.FlagJavaPath, "/usr/bin/java", "java path")
 	c := *cli.NewContext(&app, set, nil)
 
 	set.Bool("compile", false, "compile stuff")
@@ -80,3 +87,174 @@ func TestAnalyzeNoCompile(t *testing.T) {
 
 	require.Equal(t, want, got)
 }
+
+func TestAnalyzeProjects(t *testing.T) {
+	repositoryPath := filepath.Join("test", "fixtures")
+	projects, err := project.FindProjects(repositoryPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := *newMockApp()
+	set := flag.NewFlagSet("analyze", 0)
+	set.Int("concurrency", 4, "concurrency")
+	c := *cli.NewContext(&app, set, nil)
+
+	// We override analyze to return one bug instance per project, named after the project.
+	oldAnalyze := analyzeProj
+	defer func() { analyzeProj = oldAnalyze }()
+
+	analyzeProj = func(c *cli.Context, p project.Project) (instance.Instances, error) {
+		bug := instance.Instance{Type: p.Path}
+		bug.SourceLine.SourcePath = filepath.Join("com", "gitlab", "security_products", "tests", "App.java")
+		return instance.Instances{
+			Instances:   []instance.Instance{bug},
+			BugPatterns: []instance.BugPattern{{Type: "PREDICTABLE_RANDOM"}},
+		}, nil
+	}
+
+	got, err := analyzeProjects(&c, repositoryPath, projects)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string
+	for _, p := range projects {
+		if _, err := p.RelativePath(filepath.Join("com", "gitlab", "security_products", "tests", "App.java")); err == nil {
+			want = append(want, p.Path)
+		}
+	}
+
+	var gotTypes []string
+	for _, bug := range got.Instances {
+		gotTypes = append(gotTypes, bug.Type)
+	}
+
+	require.NotEmpty(t, want)
+	require.Equal(t, want, gotTypes)
+	require.Equal(t, []instance.BugPattern{{Type: "PREDICTABLE_RANDOM"}}, got.BugPatterns)
+
+	// A single failing project fails the whole analysis.
+	analyzeProj = func(c *cli.Context, p project.Project) (instance.Instances, error) {
+		if p.Path == projects[len(projects)-1].Path {
+			return instance.Instances{}, fmt.Errorf("analysis failed")
+		}
+		return instance.Instances{}, nil
+	}
+
+	_, err = analyzeProjects(&c, repositoryPath, projects)
+	require.Error(t, err)
+}
+
+func TestDeduplicate(t *testing.T) {
+	newBug := func(bugType, path string, start int) instance.Instance {
+		bug := instance.Instance{Type: bugType, InstanceHash: "1234"}
+		bug.SourceLine.SourcePath = path
+		bug.SourceLine.Start = start
+		bug.SourceLine.End = start
+		return bug
+	}
+
+	bugs := []instance.Instance{
+		newBug("PREDICTABLE_RANDOM", "api/App.java", 12),
+		newBug("PREDICTABLE_RANDOM", "web/App.java", 12),
+		newBug("PREDICTABLE_RANDOM", "api/App.java", 12),
+		newBug("PREDICTABLE_RANDOM", "api/App.java", 13),
+		newBug("SQL_INJECTION", "api/App.java", 12),
+	}
+
+	want := []instance.Instance{bugs[0], bugs[1], bugs[3], bugs[4]}
+	require.Equal(t, want, deduplicate(bugs))
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for format, wantErr := range map[string]bool{"": false, "gitlab": false, "sarif": false, "html": true} {
+		set := flag.NewFlagSet("analyze", 0)
+		set.String(flagOutputFormat, format, "")
+		c := cli.NewContext(nil, set, nil)
+
+		err := validateOutputFormat(c)
+		require.Equal(t, wantErr, err != nil, "format %q", format)
+	}
+}
+
+func TestBuildJarsList(t *testing.T) {
+	projects, err := project.FindProjects(filepath.Join("test", "fixtures", "maven-project"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Len(t, projects, 1)
+
+	localRepo, err := ioutil.TempDir("", "m2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(localRepo)
+
+	jar := filepath.Join(localRepo, "junit", "junit", "4.12", "junit-4.12.jar")
+	require.NoError(t, os.MkdirAll(filepath.Dir(jar), 0755))
+	require.NoError(t, ioutil.WriteFile(jar, nil, 0644))
+
+	app := *newMockApp()
+	set := flag.NewFlagSet("analyze", 0)
+	set.String(project.FlagMavenRepoPath, localRepo, "local repository")
+	c := *cli.NewContext(&app, set, nil)
+
+	ws, err := newWorkspace(&c, projects[0].Path)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	oldResolve := resolveClasspath
+	defer func() { resolveClasspath = oldResolve }()
+
+	t.Run("resolved classpath", func(t *testing.T) {
+		resolveClasspath = func(c *cli.Context, p *project.Project, outputFile string) ([]string, error) {
+			require.Equal(t, ws.Classpath(), outputFile)
+			return []string{"/deps/a.jar", "/deps/b.jar"}, nil
+		}
+
+		require.NoError(t, buildJarsList(&c, &projects[0], ws))
+
+		got, err := ioutil.ReadFile(ws.JarsList())
+		require.NoError(t, err)
+		require.Equal(t, "/deps/a.jar\n/deps/b.jar\n", string(got))
+	})
+
+	t.Run("local repository fallback", func(t *testing.T) {
+		resolveClasspath = func(c *cli.Context, p *project.Project, outputFile string) ([]string, error) {
+			return nil, fmt.Errorf("dependency plugin failed")
+		}
+
+		require.NoError(t, buildJarsList(&c, &projects[0], ws))
+
+		got, err := ioutil.ReadFile(ws.JarsList())
+		require.NoError(t, err)
+		require.Equal(t, jar+"\n", string(got))
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repository")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	app := *newMockApp()
+	set := flag.NewFlagSet("analyze", 0)
+	set.String(flagConfigFile, ".spotbugs-analyzer.yml", "config file")
+	c := *cli.NewContext(&app, set, nil)
+
+	// A missing configuration file doesn't change anything.
+	cfg, err := loadConfig(&c, dir)
+	require.NoError(t, err)
+	require.Empty(t, cfg.Overrides())
+
+	content := "projects:\n  - path: examples\n    skip: true\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".spotbugs-analyzer.yml"), []byte(content), 0644))
+	cfg, err = loadConfig(&c, dir)
+	require.NoError(t, err)
+	require.Equal(t, project.Overrides{"examples": {Skip: true}}, cfg.Overrides())
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".spotbugs-analyzer.yml"), []byte("projects:\n  - path: examples\n    skipped: true\n"), 0644))
+	_, err = loadConfig(&c, dir)
+	require.Error(t, err)
+}
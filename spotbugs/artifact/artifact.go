@@ -0,0 +1,237 @@
+// Package artifact unpacks prebuilt Java archives (JAR, WAR and EAR files), so that they can be analyzed
+// without building their sources.
+package artifact
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	extEAR = ".ear"
+	extJAR = ".jar"
+	extWAR = ".war"
+
+	// suffixSources is the suffix of the archives containing the sources of another archive.
+	suffixSources = "-sources.jar"
+
+	dirClasses = "classes"
+	dirLib     = "lib"
+	dirModules = "modules"
+	dirSources = "sources"
+)
+
+// Archive is an unpacked archive.
+type Archive struct {
+	Path      string   // path of the archive
+	Classes   []string // jars and directories containing the application classes
+	Libraries []string // jars of the libraries used by the application
+	// SourcesArchive is the path of the sources jar found next to the archive, if any, and Sources the
+	// directory where it is extracted.
+	SourcesArchive string
+	Sources        string
+}
+
+// Find returns the archives matching the glob patterns, relative to the root directory. Archives containing
+// sources are ignored, as they're found when unpacking the archive they belong to.
+func Find(root string, patterns []string) ([]string, error) {
+	found := make(map[string]bool)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			if IsArchive(match) {
+				found[match] = true
+			}
+		}
+	}
+
+	archives := make([]string, 0, len(found))
+	for archive := range found {
+		archives = append(archives, archive)
+	}
+	sort.Strings(archives)
+
+	return archives, nil
+}
+
+// IsArchive returns true if the file is a JAR, WAR or EAR file that doesn't contain sources.
+func IsArchive(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case extEAR, extWAR:
+		return true
+	case extJAR:
+		return !strings.HasSuffix(strings.ToLower(filename), suffixSources)
+	default:
+		return false
+	}
+}
+
+// Unpack unpacks the archive into the destination directory. JAR files are analyzed as is; the classes and
+// libraries of WAR files are extracted from WEB-INF/classes and WEB-INF/lib; the modules of EAR files are
+// unpacked recursively, and the jars of their lib directory are used as libraries.
+// The sources of the archive are extracted if a sources jar is found next to it.
+func Unpack(archivePath, dest string) (*Archive, error) {
+	archive := &Archive{Path: archivePath}
+
+	var err error
+	switch strings.ToLower(filepath.Ext(archivePath)) {
+	case extJAR:
+		archive.Classes = []string{archivePath}
+	case extWAR:
+		err = unpackWAR(archive, archivePath, dest)
+	case extEAR:
+		err = unpackEAR(archive, archivePath, dest)
+	default:
+		err = fmt.Errorf("unsupported archive %s", archivePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sourcesJar := strings.TrimSuffix(archivePath, filepath.Ext(archivePath)) + suffixSources
+	if _, err := os.Stat(sourcesJar); err == nil {
+		sourcesDir := filepath.Join(dest, dirSources)
+		if err := extract(sourcesJar, sourcesDir, func(name string) (string, bool) { return name, true }); err != nil {
+			return nil, err
+		}
+		archive.SourcesArchive = sourcesJar
+		archive.Sources = sourcesDir
+	}
+
+	return archive, nil
+}
+
+// unpackWAR extracts the classes and libraries of a web application.
+func unpackWAR(archive *Archive, warPath, dest string) error {
+	classesDir := filepath.Join(dest, dirClasses)
+	hasClasses := false
+
+	err := extract(warPath, dest, func(name string) (string, bool) {
+		if strings.HasPrefix(name, "WEB-INF/classes/") {
+			hasClasses = true
+			return path.Join(dirClasses, strings.TrimPrefix(name, "WEB-INF/classes/")), true
+		}
+
+		if strings.HasPrefix(name, "WEB-INF/lib/") && strings.HasSuffix(name, extJAR) {
+			jar := path.Join(dirLib, path.Base(name))
+			archive.Libraries = append(archive.Libraries, filepath.Join(dest, filepath.FromSlash(jar)))
+			return jar, true
+		}
+
+		return "", false
+	})
+	if err != nil {
+		return err
+	}
+
+	if hasClasses {
+		archive.Classes = append(archive.Classes, classesDir)
+	}
+
+	return nil
+}
+
+// unpackEAR extracts the modules and libraries of an enterprise application.
+func unpackEAR(archive *Archive, earPath, dest string) error {
+	var wars []string
+	err := extract(earPath, dest, func(name string) (string, bool) {
+		switch {
+		case strings.HasPrefix(name, dirLib+"/") && strings.HasSuffix(name, extJAR):
+			archive.Libraries = append(archive.Libraries, filepath.Join(dest, filepath.FromSlash(name)))
+			return name, true
+		case !strings.Contains(name, "/") && strings.HasSuffix(name, extWAR):
+			module := path.Join(dirModules, name)
+			wars = append(wars, filepath.Join(dest, filepath.FromSlash(module)))
+			return module, true
+		case !strings.Contains(name, "/") && strings.HasSuffix(name, extJAR):
+			module := path.Join(dirModules, name)
+			archive.Classes = append(archive.Classes, filepath.Join(dest, filepath.FromSlash(module)))
+			return module, true
+		default:
+			return "", false
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, war := range wars {
+		if err := unpackWAR(archive, war, strings.TrimSuffix(war, extWAR)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extract writes the entries of a zip archive selected by the given function into the destination directory.
+// The function returns the path of the entry in the destination directory, and whether it's extracted.
+func extract(zipPath, dest string, selectEntry func(name string) (string, bool)) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name, ok := selectEntry(f.Name)
+		if !ok {
+			continue
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		if rel, err := filepath.Rel(dest, target); err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("invalid entry %s in %s", f.Name, zipPath)
+		}
+
+		if err := extractFile(f, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractFile writes a zip archive entry to the target path.
+func extractFile(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
@@ -0,0 +1,157 @@
+package artifact
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/testutil"
+)
+
+// zipContent returns a zip archive with the given entries, each containing its own name.
+func zipContent(t *testing.T, entries map[string][]byte) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range entries {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestFind(t *testing.T) {
+	dir, cleanup := testutil.TempDir(t, "artifact")
+	defer cleanup()
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		"app.jar":         "",
+		"app-sources.jar": "",
+		"web/shop.war":    "",
+		"web/readme.txt":  "",
+		"dist/bank.ear":   "",
+	})
+
+	got, err := Find(dir, []string{"*.jar", " web/* ", "dist/*.ear", "*.jar", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "app.jar"),
+		filepath.Join(dir, "dist", "bank.ear"),
+		filepath.Join(dir, "web", "shop.war"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
+
+func TestUnpack_JAR(t *testing.T) {
+	dir, cleanup := testutil.TempDir(t, "artifact")
+	defer cleanup()
+
+	jar := filepath.Join(dir, "app-1.0.jar")
+	testutil.WriteFiles(t, dir, map[string]string{
+		"app-1.0.jar": string(zipContent(t, map[string][]byte{"com/acme/App.class": nil})),
+		"app-1.0-sources.jar": string(zipContent(t, map[string][]byte{
+			"com/acme/App.java": []byte("package com.acme;"),
+		})),
+	})
+
+	dest := filepath.Join(dir, "unpacked")
+	got, err := Unpack(jar, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Archive{
+		Path:           jar,
+		Classes:        []string{jar},
+		SourcesArchive: filepath.Join(dir, "app-1.0-sources.jar"),
+		Sources:        filepath.Join(dest, "sources"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "sources", "com", "acme", "App.java")); err != nil {
+		t.Errorf("Sources weren't extracted: %s", err)
+	}
+}
+
+func TestUnpack_EAR(t *testing.T) {
+	dir, cleanup := testutil.TempDir(t, "artifact")
+	defer cleanup()
+
+	war := zipContent(t, map[string][]byte{
+		"index.jsp":                          nil,
+		"WEB-INF/web.xml":                    nil,
+		"WEB-INF/classes/com/acme/Web.class": nil,
+		"WEB-INF/lib/commons-io.jar":         nil,
+	})
+	ear := filepath.Join(dir, "bank.ear")
+	testutil.WriteFiles(t, dir, map[string]string{
+		"bank.ear": string(zipContent(t, map[string][]byte{
+			"META-INF/application.xml": nil,
+			"shop.war":                 war,
+			"ejb.jar":                  nil,
+			"lib/slf4j.jar":            nil,
+		})),
+	})
+
+	dest := filepath.Join(dir, "unpacked")
+	got, err := Unpack(ear, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Archive{
+		Path: ear,
+		Classes: []string{
+			filepath.Join(dest, "modules", "ejb.jar"),
+			filepath.Join(dest, "modules", "shop", "classes"),
+		},
+		Libraries: []string{
+			filepath.Join(dest, "lib", "slf4j.jar"),
+			filepath.Join(dest, "modules", "shop", "lib", "commons-io.jar"),
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+
+	for _, path := range append(want.Classes, want.Libraries...) {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s wasn't extracted: %s", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "modules", "shop", "classes", "com", "acme", "Web.class")); err != nil {
+		t.Errorf("Classes weren't extracted: %s", err)
+	}
+}
+
+func TestUnpack_InvalidEntry(t *testing.T) {
+	dir, cleanup := testutil.TempDir(t, "artifact")
+	defer cleanup()
+
+	war := filepath.Join(dir, "evil.war")
+	testutil.WriteFiles(t, dir, map[string]string{
+		"evil.war": string(zipContent(t, map[string][]byte{"WEB-INF/classes/../../../../evil.class": nil})),
+	})
+
+	if _, err := Unpack(war, filepath.Join(dir, "unpacked")); err == nil {
+		t.Error("Expected an error for an entry outside of the destination")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/artifact"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+// dirUnpacked is the directory of the workspace where archives are unpacked.
+const dirUnpacked = "unpacked"
+
+// archiveSeparator separates the path of an archive from the path of a file it contains.
+const archiveSeparator = "!/"
+
+// analyzeArtifacts runs SpotBugs on the prebuilt archives matching the artifact patterns, instead of building
+// and analyzing projects.
+func analyzeArtifacts(c *cli.Context, repositoryPath string) (instance.Instances, error) {
+	archives, err := artifact.Find(repositoryPath, strings.Split(c.String(flagArtifacts), ","))
+	if err != nil {
+		log.Errorf("Error: Invalid artifact patterns %s: %s\n", c.String(flagArtifacts), err.Error())
+		return instance.Instances{}, err
+	}
+
+	log.Infof("Found %d analyzable artifacts.\n", len(archives))
+
+	// The source files of the repository, if any, are used to locate the bug instances.
+	sources, err := project.NewSourceProject(repositoryPath)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+
+	return analyzeConcurrently(c, len(archives), func(i int) (instance.Instances, error) {
+		return analyzeArtifact(c, repositoryPath, sources, archives[i])
+	})
+}
+
+// analyzeArtifact unpacks an archive in a workspace, runs SpotBugs on its application classes with its libraries
+// on the auxiliary classpath, and locates the reported source files.
+func analyzeArtifact(c *cli.Context, repositoryPath string, sources *project.Project, archivePath string) (instance.Instances, error) {
+	ws, err := newWorkspace(c, archivePath)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+	defer utils.WithWarning(fmt.Sprintf("Couldn't remove workspace %s", ws.Path), ws.Close)
+
+	archive, err := artifact.Unpack(archivePath, filepath.Join(ws.Path, dirUnpacked))
+	if err != nil {
+		log.Errorf("Error: Couldn't unpack %s: %s\n", archivePath, err.Error())
+		return instance.Instances{}, err
+	}
+
+	if len(archive.Classes) == 0 {
+		log.Warnf("No application classes found in %s.\n", archivePath)
+		return instance.Instances{}, nil
+	}
+
+	if err := writeJarsList(ws, archive.Libraries); err != nil {
+		return instance.Instances{}, err
+	}
+
+	report, err := runSpotBugs(c, ws.Path, ws, nil, archive.Classes)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+
+	report.Instances, err = locateArtifactSources(repositoryPath, sources, archive, report.Instances)
+	if err != nil {
+		return instance.Instances{}, err
+	}
+
+	// Hash the source lines of each issue, used to track issues across changes.
+	instance.HashSources(repositoryPath, report.Instances)
+
+	// Honor the suppression comments of the source files.
+	report.Instances = suppressInSource(c, repositoryPath, report.Instances)
+
+	return report, nil
+}
+
+// locateArtifactSources sets the source path of the bug instances to the matching source file of the repository.
+// Source files that aren't in the repository are located in the sources jar of the archive if it contains them,
+// or in the archive itself, as <archive path>!/<reported path>.
+func locateArtifactSources(repositoryPath string, sources *project.Project, archive *artifact.Archive, bugInstances []instance.Instance) ([]instance.Instance, error) {
+	archivePath, err := filepath.Rel(repositoryPath, archive.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var archiveSources *project.Project
+	var sourcesArchivePath string
+	if archive.Sources != "" {
+		if archiveSources, err = project.NewSourceProject(archive.Sources); err != nil {
+			return nil, err
+		}
+
+		if sourcesArchivePath, err = filepath.Rel(repositoryPath, archive.SourcesArchive); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range bugInstances {
+		sourceLine := &bugInstances[i].SourceLine
+		if relPath, err := sources.RelativePath(sourceLine.SourcePath); err == nil {
+			sourceLine.SourcePath = relPath
+			continue
+		}
+
+		if archiveSources != nil {
+			if relPath, err := archiveSources.RelativePath(sourceLine.SourcePath); err == nil {
+				sourceLine.SourcePath = filepath.ToSlash(sourcesArchivePath) + archiveSeparator + filepath.ToSlash(relPath)
+				continue
+			}
+		}
+
+		sourceLine.SourcePath = filepath.ToSlash(archivePath) + archiveSeparator + filepath.ToSlash(sourceLine.SourcePath)
+	}
+
+	return bugInstances, nil
+}
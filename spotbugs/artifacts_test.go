@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/artifact"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+)
+
+func TestLocateArtifactSources(t *testing.T) {
+	repositoryPath := filepath.Join("test", "fixtures", "maven-project")
+	sources, err := project.NewSourceProject(repositoryPath)
+	require.NoError(t, err)
+
+	newBug := func(path string) instance.Instance {
+		bug := instance.Instance{}
+		bug.SourceLine.SourcePath = path
+		return bug
+	}
+
+	// The sources jar of the archive is extracted in the fixtures of another project.
+	archive := &artifact.Archive{
+		Path:           filepath.Join(repositoryPath, "dist", "app.war"),
+		SourcesArchive: filepath.Join(repositoryPath, "dist", "app-sources.jar"),
+		Sources:        filepath.Join("test", "fixtures", "kotlin-project", "src", "main", "kotlin"),
+	}
+
+	got, err := locateArtifactSources(repositoryPath, sources, archive, []instance.Instance{
+		newBug("com/gitlab/security_products/tests/App.java"),
+		newBug("com/gitlab/security_products/object/util/Tokens.kt"),
+		newBug("org/apache/Library.java"),
+	})
+	require.NoError(t, err)
+
+	var paths []string
+	for _, bug := range got {
+		paths = append(paths, bug.SourceLine.SourcePath)
+	}
+
+	want := []string{
+		filepath.Join("src", "main", "java", "com", "gitlab", "security_products", "tests", "App.java"),
+		"dist/app-sources.jar!/util/Tokens.kt",
+		"dist/app.war!/org/apache/Library.java",
+	}
+	require.Equal(t, want, paths)
+}
+
+func TestAnalyzeArtifacts_NoArchive(t *testing.T) {
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+
+	app := *newMockApp()
+	set := flag.NewFlagSet("analyze", 0)
+	set.String(flagArtifacts, "*.ear,target/*.war", "artifacts")
+	c := *cli.NewContext(&app, set, nil)
+
+	got, err := analyzeArtifacts(&c, filepath.Join(dir, "test", "fixtures", "maven-project"))
+	require.NoError(t, err)
+	require.Empty(t, got.Instances)
+}
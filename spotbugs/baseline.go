@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/command"
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/pathfilter"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/baseline"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+const (
+	baselineModeDrop     = "drop"
+	baselineModeSuppress = "suppress"
+	fileBaseline         = ".spotbugs-baseline.json"
+)
+
+// baselineCommand returns a cli sub-command that analyzes a project and writes its findings to a baseline file.
+func baselineCommand() cli.Command {
+	return cli.Command{
+		Name:      "baseline",
+		Aliases:   []string{"b"},
+		Usage:     "Analyze detected project and write its findings to a baseline file",
+		ArgsUsage: "<project-dir>",
+		Flags:     append(analyzeFlags(), pathfilter.MakeFlags("SAST_")...),
+		Action: func(c *cli.Context) error {
+			if len(c.Args()) != 1 {
+				cli.ShowSubcommandHelp(c)
+				return &command.ErrInvalidArgs{}
+			}
+
+			repositoryPath, err := filepath.Abs(c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			finalReport, err := findBugInstances(c, repositoryPath, nil)
+			if err != nil {
+				return err
+			}
+
+			return writeBaseline(baselinePath(c, repositoryPath), finalReport.Instances)
+		},
+	}
+}
+
+// baselinePath returns the path of the baseline file. Relative paths are relative to the repository.
+func baselinePath(c *cli.Context, repositoryPath string) string {
+	path := c.String(flagBaseline)
+	if path == "" {
+		path = fileBaseline
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repositoryPath, path)
+	}
+
+	return path
+}
+
+// writeBaseline writes the bug instances to a baseline file.
+func writeBaseline(path string, bugInstances []instance.Instance) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		log.Errorf("Error: Unable to create baseline %s: %s\n", path, err.Error())
+		return err
+	}
+	defer utils.WithWarning(fmt.Sprintf("Couldn't close %s", path), f.Close)
+
+	log.Infof("Writing %d findings to baseline %s\n", len(bugInstances), path)
+	return baseline.New(bugInstances).Write(f)
+}
+
+// validateBaselineMode returns an error if the baseline mode isn't supported.
+func validateBaselineMode(c *cli.Context) error {
+	switch c.String(flagBaselineMode) {
+	case "", baselineModeDrop, baselineModeSuppress:
+		return nil
+	default:
+		return fmt.Errorf(
+			"baseline mode %s is not supported. Valid values are %s, %s",
+			c.String(flagBaselineMode), baselineModeDrop, baselineModeSuppress)
+	}
+}
+
+// applyBaseline drops the bug instances present in the baseline file, or marks them as suppressed.
+func applyBaseline(c *cli.Context, repositoryPath string, bugInstances []instance.Instance) ([]instance.Instance, error) {
+	path := baselinePath(c, repositoryPath)
+	b, err := baseline.Load(path)
+	if err != nil {
+		log.Errorf("Error: Unable to load baseline %s: %s\n", path, err.Error())
+		return nil, err
+	}
+
+	suppress := c.String(flagBaselineMode) == baselineModeSuppress
+	matched := b.Match(bugInstances)
+
+	result := make([]instance.Instance, 0, len(bugInstances))
+	count := 0
+	for i, bug := range bugInstances {
+		if matched[i] {
+			count++
+			if !suppress {
+				continue
+			}
+			bug.Suppressed = true
+		}
+		result = append(result, bug)
+	}
+
+	log.Infof("%d of %d findings are in baseline %s\n", count, len(bugInstances), path)
+	return result, nil
+}
@@ -0,0 +1,121 @@
+// Package baseline provides the Baseline type, listing already triaged bug instances so that only new ones
+// are reported.
+//
+// A bug instance matches a baseline finding when their compare keys are equal, or failing that when their
+// tracking keys are equal, which don't change when lines are added or removed elsewhere in the file. Each finding
+// of the baseline matches at most one bug instance, so that a new bug instance of the same type in the same method
+// is still reported.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+)
+
+// CurrentVersion is the version of the baseline file format.
+const CurrentVersion = 1
+
+// Baseline is the content of a baseline file.
+type Baseline struct {
+	Version  int       `json:"version"`
+	Findings []Finding `json:"findings"`
+}
+
+// Finding identifies a triaged bug instance. Type, File and Line are only informative.
+type Finding struct {
+	CompareKey  string `json:"compare_key"`
+	TrackingKey string `json:"tracking_key,omitempty"`
+	Type        string `json:"type"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+}
+
+// New returns a baseline containing the given bug instances.
+func New(bugInstances []instance.Instance) *Baseline {
+	findings := make([]Finding, len(bugInstances))
+	for i, bug := range bugInstances {
+		findings[i] = Finding{
+			CompareKey:  bug.CompareKey(),
+			TrackingKey: bug.TrackingKey(),
+			Type:        bug.Type,
+			File:        bug.SourceLine.SourcePath,
+			Line:        bug.SourceLine.Start,
+		}
+	}
+
+	return &Baseline{Version: CurrentVersion, Findings: findings}
+}
+
+// Read decodes a baseline.
+func Read(r io.Reader) (*Baseline, error) {
+	b := &Baseline{}
+	if err := json.NewDecoder(r).Decode(b); err != nil {
+		return nil, err
+	}
+
+	if b.Version != CurrentVersion {
+		return nil, fmt.Errorf("baseline version %d is not supported. Valid value is %d", b.Version, CurrentVersion)
+	}
+
+	return b, nil
+}
+
+// Load reads the baseline file at the given path.
+func Load(path string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read baseline %s: %v", path, err)
+	}
+
+	return b, nil
+}
+
+// Write encodes the baseline as indented JSON.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// Match returns, for each bug instance, whether it matches a finding of the baseline.
+func (b *Baseline) Match(bugInstances []instance.Instance) []bool {
+	matched := make([]bool, len(bugInstances))
+	used := make([]bool, len(b.Findings))
+
+	// Exact matches first, so that they can't be consumed by a tracking key match of another bug instance.
+	b.match(bugInstances, matched, used, func(f Finding, bug instance.Instance) bool {
+		return f.CompareKey != "" && f.CompareKey == bug.CompareKey()
+	})
+
+	b.match(bugInstances, matched, used, func(f Finding, bug instance.Instance) bool {
+		return f.TrackingKey != "" && f.TrackingKey == bug.TrackingKey()
+	})
+
+	return matched
+}
+
+func (b *Baseline) match(bugInstances []instance.Instance, matched, used []bool, equal func(Finding, instance.Instance) bool) {
+	for i, bug := range bugInstances {
+		if matched[i] {
+			continue
+		}
+
+		for j, f := range b.Findings {
+			if !used[j] && equal(f, bug) {
+				matched[i] = true
+				used[j] = true
+				break
+			}
+		}
+	}
+}
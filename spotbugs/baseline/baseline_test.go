@@ -0,0 +1,105 @@
+package baseline
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+)
+
+func newInstance(hash, method, source string, line int) instance.Instance {
+	bug := instance.Instance{Type: "SQL_INJECTION_JDBC", InstanceHash: hash, SourceHash: source}
+	bug.Class.Name = "com.gitlab.App"
+	bug.Method.Name = method
+	bug.SourceLine = instance.SourceLine{Start: line, End: line, SourcePath: "src/main/java/com/gitlab/App.java"}
+	return bug
+}
+
+func TestBaseline_Match(t *testing.T) {
+	b := New([]instance.Instance{
+		newInstance("aaaa", "query", "select", 10),
+		newInstance("bbbb", "update", "update", 20),
+		newInstance("eeee", "unread", "", 40),
+	})
+
+	tests := []struct {
+		name         string
+		bugInstances []instance.Instance
+		want         []bool
+	}{
+		{
+			name: "Unchanged",
+			bugInstances: []instance.Instance{
+				newInstance("aaaa", "query", "select", 10),
+				newInstance("bbbb", "update", "update", 20),
+				newInstance("eeee", "unread", "", 40),
+			},
+			want: []bool{true, true, true},
+		},
+		{
+			name: "Shifted lines",
+			bugInstances: []instance.Instance{
+				newInstance("aaaa", "query", "select", 12),
+				newInstance("bbbb", "update", "update", 22),
+			},
+			want: []bool{true, true},
+		},
+		{
+			name:         "Changed source",
+			bugInstances: []instance.Instance{newInstance("aaaa", "query", "select *", 12)},
+			want:         []bool{false},
+		},
+		{
+			name:         "Shifted lines without source hash",
+			bugInstances: []instance.Instance{newInstance("eeee", "unread", "", 42)},
+			want:         []bool{false},
+		},
+		{
+			name: "New instance in a baselined method",
+			bugInstances: []instance.Instance{
+				newInstance("cccc", "query", "select", 11),
+				newInstance("aaaa", "query", "select", 10),
+			},
+			want: []bool{false, true},
+		},
+		{
+			name:         "New method",
+			bugInstances: []instance.Instance{newInstance("dddd", "delete", "delete", 30)},
+			want:         []bool{false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := b.Match(tt.bugInstances)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Baseline.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseline_WriteRead(t *testing.T) {
+	want := New([]instance.Instance{newInstance("aaaa", "query", "select", 10)})
+
+	var buf bytes.Buffer
+	if err := want.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %#v, want %#v", got, want)
+	}
+}
+
+func TestRead_UnsupportedVersion(t *testing.T) {
+	if _, err := Read(strings.NewReader(`{"version": 2, "findings": []}`)); err == nil {
+		t.Error("Read() didn't fail on an unsupported version")
+	}
+}
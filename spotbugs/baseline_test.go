@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+)
+
+func TestApplyBaseline(t *testing.T) {
+	repositoryPath, err := ioutil.TempDir("", "test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryPath)
+
+	triaged := instance.Instance{Type: "PREDICTABLE_RANDOM", InstanceHash: "aaaa"}
+	triaged.SourceLine.SourcePath = "src/main/java/App.java"
+	fresh := instance.Instance{Type: "SQL_INJECTION_JDBC", InstanceHash: "bbbb"}
+	fresh.SourceLine.SourcePath = "src/main/java/App.java"
+
+	require.NoError(t, writeBaseline(filepath.Join(repositoryPath, fileBaseline), []instance.Instance{triaged}))
+
+	tests := []struct {
+		mode string
+		want []instance.Instance
+	}{
+		{
+			mode: baselineModeDrop,
+			want: []instance.Instance{fresh},
+		},
+		{
+			mode: baselineModeSuppress,
+			want: func() []instance.Instance {
+				suppressed := triaged
+				suppressed.Suppressed = true
+				return []instance.Instance{suppressed, fresh}
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			set := flag.NewFlagSet("analyze", 0)
+			set.String(flagBaseline, fileBaseline, "")
+			set.String(flagBaselineMode, tt.mode, "")
+			c := cli.NewContext(nil, set, nil)
+
+			got, err := applyBaseline(c, repositoryPath, []instance.Instance{triaged, fresh})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyBaseline_Missing(t *testing.T) {
+	set := flag.NewFlagSet("analyze", 0)
+	set.String(flagBaseline, "missing.json", "")
+	c := cli.NewContext(nil, set, nil)
+
+	_, err := applyBaseline(c, os.TempDir(), nil)
+	require.Error(t, err)
+}
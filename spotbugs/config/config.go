@@ -0,0 +1,144 @@
+// Package config reads the configuration file of a repository, which changes how the analyzer finds and builds
+// its projects.
+//
+// Example:
+//
+//	projects:
+//	  - path: services/billing
+//	    builder: gradle
+//	    javaVersion: "11"
+//	  - path: legacy
+//	    buildCommand: ["./build.sh", "--skip-tests"]
+//	  - path: examples
+//	    skip: true
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/sdkman"
+)
+
+// DefaultFilename is the name of the configuration file, at the root of the repository.
+const DefaultFilename = ".spotbugs-analyzer.yml"
+
+// Config is the content of a configuration file.
+type Config struct {
+	Projects []Project `yaml:"projects"`
+}
+
+// Project configures how the project of a directory is found and built.
+type Project struct {
+	Path         string   `yaml:"path"`         // directory relative to the repository root
+	Builder      string   `yaml:"builder"`      // name of the builder to use instead of the detected one
+	BuildCommand []string `yaml:"buildCommand"` // command and arguments building the project
+	JavaVersion  string   `yaml:"javaVersion"`  // major Java version or SDKMAN identifier building the project
+	Skip         bool     `yaml:"skip"`         // don't analyze the directory and its descendants
+}
+
+// Load reads and validates the configuration file at the given path.
+// It returns an empty configuration if the file doesn't exist.
+func Load(filename string) (*Config, error) {
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %s", filename, err.Error())
+	}
+
+	return cfg, nil
+}
+
+// Parse reads and validates a configuration. Unknown keys are reported as errors.
+func Parse(r io.Reader) (*Config, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return cfg, nil
+	}
+
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate returns an error listing all the invalid settings of the configuration.
+func (c *Config) Validate() error {
+	var errs []string
+	paths := make(map[string]bool)
+
+	for i, p := range c.Projects {
+		prefix := fmt.Sprintf("projects[%d]", i)
+		if p.Path == "" {
+			errs = append(errs, prefix+": path is required")
+			continue
+		}
+
+		cleanPath := path.Clean(p.Path)
+		if path.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+			errs = append(errs, fmt.Sprintf("%s: path %s must be relative to the repository root", prefix, p.Path))
+		}
+		if paths[cleanPath] {
+			errs = append(errs, fmt.Sprintf("%s: path %s is configured more than once", prefix, p.Path))
+		}
+		paths[cleanPath] = true
+
+		if p.Skip && (p.Builder != "" || len(p.BuildCommand) > 0 || p.JavaVersion != "") {
+			errs = append(errs, fmt.Sprintf("%s: skipped path %s can't have other settings", prefix, p.Path))
+		}
+		if p.Builder != "" && !project.IsBuilderName(p.Builder) {
+			errs = append(errs, fmt.Sprintf("%s: unknown builder %s, valid values are %s",
+				prefix, p.Builder, strings.Join(project.BuilderNames(), ", ")))
+		}
+		if p.BuildCommand != nil && (len(p.BuildCommand) == 0 || p.BuildCommand[0] == "") {
+			errs = append(errs, fmt.Sprintf("%s: buildCommand can't be empty", prefix))
+		}
+		if p.JavaVersion != "" && !sdkman.IsSupportedJavaVersion(p.JavaVersion) {
+			errs = append(errs, fmt.Sprintf("%s: Java version %s is not supported, valid values are %s or an SDKMAN identifier",
+				prefix, p.JavaVersion, strings.Join(sdkman.SupportedJavaVersions, ", ")))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Overrides returns the overrides of the configured directories.
+func (c *Config) Overrides() project.Overrides {
+	overrides := make(project.Overrides, len(c.Projects))
+	for _, p := range c.Projects {
+		overrides[path.Clean(p.Path)] = project.Override{
+			Builder:      p.Builder,
+			BuildCommand: p.BuildCommand,
+			JavaVersion:  p.JavaVersion,
+			Skip:         p.Skip,
+		}
+	}
+
+	return overrides
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+)
+
+func TestParse(t *testing.T) {
+	content := `
+projects:
+  - path: services/billing/
+    builder: gradle
+    javaVersion: 11
+  - path: legacy
+    buildCommand: ["./build.sh", "--skip-tests"]
+  - path: examples
+    skip: true
+`
+	cfg, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := project.Overrides{
+		"services/billing": {Builder: "gradle", JavaVersion: "11"},
+		"legacy":           {BuildCommand: []string{"./build.sh", "--skip-tests"}},
+		"examples":         {Skip: true},
+	}
+	if got := cfg.Overrides(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "Unknown key",
+			content: "projects:\n  - path: app\n    bulder: maven\n",
+			wantErr: "field bulder not found",
+		},
+		{
+			name:    "Unknown top level key",
+			content: "project:\n  - path: app\n",
+			wantErr: "field project not found",
+		},
+		{
+			name:    "Missing path",
+			content: "projects:\n  - builder: maven\n",
+			wantErr: "projects[0]: path is required",
+		},
+		{
+			name:    "Path outside of the repository",
+			content: "projects:\n  - path: ../app\n    skip: true\n",
+			wantErr: "must be relative to the repository root",
+		},
+		{
+			name:    "Duplicate path",
+			content: "projects:\n  - path: app\n    skip: true\n  - path: app/\n    skip: true\n",
+			wantErr: "projects[1]: path app/ is configured more than once",
+		},
+		{
+			name:    "Unknown builder",
+			content: "projects:\n  - path: app\n    builder: make\n",
+			wantErr: "unknown builder make",
+		},
+		{
+			name:    "Skip with other settings",
+			content: "projects:\n  - path: app\n    skip: true\n    builder: maven\n",
+			wantErr: "skipped path app can't have other settings",
+		},
+		{
+			name:    "Empty command",
+			content: "projects:\n  - path: app\n    buildCommand: []\n",
+			wantErr: "buildCommand can't be empty",
+		},
+		{
+			name:    "Unsupported Java version",
+			content: "projects:\n  - path: app\n    javaVersion: 7\n",
+			wantErr: "Java version 7 is not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A missing file is an empty configuration.
+	cfg, err := Load(filepath.Join(dir, DefaultFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Projects) != 0 {
+		t.Errorf("Expected an empty configuration but got %#v", cfg)
+	}
+
+	filename := filepath.Join(dir, DefaultFilename)
+	if err := ioutil.WriteFile(filename, []byte("projects: {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(filename); err == nil || !strings.Contains(err.Error(), filename) {
+		t.Errorf("Expected an error naming the file but got %v", err)
+	}
+}
@@ -12,17 +12,24 @@ import (
 
 // Convert translate a SpotBugs XML report into a issue.Report.
 func Convert(reader io.Reader, prependPath string) (*issue.Report, error) {
-	var doc = struct {
-		BugInstances []instance.Instance `xml:"BugInstance"`
-	}{}
+	var doc = instance.Instances{}
 
 	err := xml.NewDecoder(reader).Decode(&doc)
 	if err != nil {
 		return nil, err
 	}
 
-	issues := make([]issue.Issue, len(doc.BugInstances))
-	for i, bug := range doc.BugInstances {
+	issues := make([]issue.Issue, len(doc.Instances))
+	for i, bug := range doc.Instances {
+		// Solution and links are extracted from the details of the bug pattern.
+		pattern, _ := doc.BugPattern(bug.Type)
+
+		confidence := bug.Confidence()
+		if bug.Suppressed {
+			// The issue is in the baseline or suppressed in the source: it has already been triaged.
+			confidence = issue.ConfidenceLevelIgnore
+		}
+
 		issues[i] = issue.Issue{
 			Category:    metadata.Type,
 			Scanner:     metadata.IssueScanner,
@@ -31,11 +38,11 @@ func Convert(reader io.Reader, prependPath string) (*issue.Report, error) {
 			Description: bug.LongMessage, // Could be extracted from BugPattern/Details instead
 			CompareKey:  bug.CompareKey(),
 			Severity:    bug.Severity(),
-			Confidence:  bug.Confidence(),
-			// Solution: bug.Solution(), Need to parse BugPattern/Details to extract solution
+			Confidence:  confidence,
+			Solution:    pattern.Solution(),
 			Location:    bug.Location(prependPath),
 			Identifiers: bug.Identifiers(),
-			// Links:    bug.Links(), Need to parse BugPattern/Details to extract links
+			Links:       pattern.Links(),
 		}
 	}
 
@@ -194,6 +194,8 @@ func TestConvert(t *testing.T) {
 				CompareKey:  "e6449b89335daf53c0db4c0219bc1634:CIPHER_INTEGRITY:src/main/java/com/gitlab/security_products/tests/App.java:29",
 				Severity:    issue.SeverityLevelMedium,
 				Confidence:  issue.ConfidenceLevelHigh,
+				Solution: "Cipher c = Cipher.getInstance(\"AES/GCM/NoPadding\"); c.init(Cipher.ENCRYPT_MODE, k, iv); byte[] cipherText = c.doFinal(plainText);\n" +
+					"In the example solution above, the GCM mode introduces an HMAC into the resulting encrypted data, providing integrity of the result.",
 				Location: issue.Location{
 					File:      "app/src/main/java/com/gitlab/security_products/tests/App.java",
 					LineStart: 29,
@@ -215,6 +217,12 @@ func TestConvert(t *testing.T) {
 						URL:   "https://cwe.mitre.org/data/definitions/353.html",
 					},
 				},
+				Links: []issue.Link{
+					{Name: "Wikipedia: Authenticated encryption", URL: "http://en.wikipedia.org/wiki/Authenticated_encryption"},
+					{Name: "NIST: Authenticated Encryption Modes", URL: "http://csrc.nist.gov/groups/ST/toolkit/BCM/modes_development.html#01"},
+					{Name: "Moxie Marlinspike's blog: The Cryptographic Doom Principle", URL: "http://www.thoughtcrime.org/blog/the-cryptographic-doom-principle/"},
+					{Name: "CWE-353: Missing Support for Integrity Check", URL: "http://cwe.mitre.org/data/definitions/353.html"},
+				},
 			},
 			{
 				Category:    issue.CategorySast,
@@ -225,6 +233,8 @@ func TestConvert(t *testing.T) {
 				CompareKey:  "818bf5dacb291e15d9e6dc3c5ac32178:PREDICTABLE_RANDOM:src/main/java/com/gitlab/security_products/tests/App.java:47",
 				Severity:    issue.SeverityLevelMedium,
 				Confidence:  issue.ConfidenceLevelMedium,
+				Solution: "import org.apache.commons.codec.binary.Hex; String generateSecretToken() { SecureRandom secRandom = new SecureRandom(); " +
+					"byte[] result = new byte[32]; secRandom.nextBytes(result); return Hex.encodeHexString(result); }",
 				Location: issue.Location{
 					File:      "app/src/main/java/com/gitlab/security_products/tests/App.java",
 					LineStart: 47,
@@ -246,6 +256,12 @@ func TestConvert(t *testing.T) {
 						URL:   "https://cwe.mitre.org/data/definitions/330.html",
 					},
 				},
+				Links: []issue.Link{
+					{Name: "Cracking Random Number Generators - Part 1 (http://jazzy.id.au)", URL: "http://jazzy.id.au/default/2010/09/20/cracking_random_number_generators_part_1.html"},
+					{Name: "CERT: MSC02-J. Generate strong random numbers", URL: "https://www.securecoding.cert.org/confluence/display/java/MSC02-J.+Generate+strong+random+numbers"},
+					{Name: "CWE-330: Use of Insufficiently Random Values", URL: "http://cwe.mitre.org/data/definitions/330.html"},
+					{Name: "Predicting Struts CSRF Token (Example of real-life vulnerability and exploitation)", URL: "http://blog.h3xstream.com/2014/12/predicting-struts-csrf-token-cve-2014.html"},
+				},
 			},
 		},
 		DependencyFiles: []issue.DependencyFile{},
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/gitdiff"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+)
+
+// computeChanges returns the source files changed since the diff base, or nil if no diff base is defined.
+func computeChanges(c *cli.Context, repositoryPath string) (*gitdiff.Changes, error) {
+	base := c.String(flagDiffBase)
+	if base == "" {
+		return nil, nil
+	}
+
+	changes, err := gitdiff.Compute(repositoryPath, base)
+	if err != nil {
+		log.Errorf("Error: Couldn't compute the files changed since %s: %s\n", base, err.Error())
+		return nil, err
+	}
+
+	log.Infof("Found %d changed source files since %s.\n", len(changes.Files()), base)
+	return changes, nil
+}
+
+// restrictToChanges returns the projects containing changed source files, limiting the packages they
+// analyze to the packages of these files.
+func restrictToChanges(repositoryPath string, projects []project.Project, changes *gitdiff.Changes) []project.Project {
+	result := make([]project.Project, 0, len(projects))
+
+	for _, p := range projects {
+		var changedFiles []string
+		for _, f := range changes.Files() {
+			relPath, err := filepath.Rel(p.Path, filepath.Join(repositoryPath, f))
+			if err != nil || strings.HasPrefix(relPath, "..") {
+				continue
+			}
+
+			if p.HasSourceFile(relPath) {
+				changedFiles = append(changedFiles, relPath)
+			}
+		}
+
+		if len(changedFiles) == 0 {
+			log.Infof("Skipping %s, it has no changed source file.\n", p.Path)
+			continue
+		}
+
+		p.RestrictToSourceFiles(changedFiles)
+		result = append(result, p)
+	}
+
+	return result
+}
+
+// filterChanges returns the bug instances located in changed files, or in changed lines if asked.
+func filterChanges(c *cli.Context, changes *gitdiff.Changes, bugInstances []instance.Instance) []instance.Instance {
+	linesOnly := c.Bool(flagDiffLinesOnly)
+
+	result := make([]instance.Instance, 0, len(bugInstances))
+	for _, bug := range bugInstances {
+		path := bug.SourceLine.SourcePath
+		if linesOnly {
+			if !changes.HasLines(path, bug.SourceLine.Start, bug.SourceLine.End) {
+				continue
+			}
+		} else if !changes.HasFile(path) {
+			continue
+		}
+
+		result = append(result, bug)
+	}
+
+	return result
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/gitdiff"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+)
+
+const mavenAppDiff = `--- a/maven-project/src/main/java/com/gitlab/security_products/tests/App.java
++++ b/maven-project/src/main/java/com/gitlab/security_products/tests/App.java
+@@ -29 +29 @@ public class App {
+-old
++new
+`
+
+func TestRestrictToChanges(t *testing.T) {
+	repositoryPath := filepath.Join("test", "fixtures")
+	projects, err := project.FindProjects(repositoryPath, true)
+	require.NoError(t, err)
+
+	changes, err := gitdiff.Parse(strings.NewReader(mavenAppDiff))
+	require.NoError(t, err)
+
+	got := restrictToChanges(repositoryPath, projects, changes)
+	require.Len(t, got, 1)
+	require.Equal(t, filepath.Join(repositoryPath, "maven-project"), got[0].Path)
+	require.Equal(t, []string{"com.gitlab.security_products.tests"}, got[0].Packages())
+}
+
+func TestFilterChanges(t *testing.T) {
+	changes, err := gitdiff.Parse(strings.NewReader(mavenAppDiff))
+	require.NoError(t, err)
+
+	path := filepath.Join("maven-project", "src", "main", "java", "com", "gitlab", "security_products", "tests", "App.java")
+	changedLine := instance.Instance{Type: "CHANGED_LINE", SourceLine: instance.SourceLine{Start: 29, End: 29, SourcePath: path}}
+	changedFile := instance.Instance{Type: "CHANGED_FILE", SourceLine: instance.SourceLine{Start: 47, End: 47, SourcePath: path}}
+	unchanged := instance.Instance{Type: "UNCHANGED", SourceLine: instance.SourceLine{Start: 29, End: 29, SourcePath: "Other.java"}}
+	bugInstances := []instance.Instance{changedLine, changedFile, unchanged}
+
+	for linesOnly, want := range map[bool][]instance.Instance{
+		false: {changedLine, changedFile},
+		true:  {changedLine},
+	} {
+		set := flag.NewFlagSet("analyze", 0)
+		set.Bool(flagDiffLinesOnly, linesOnly, "")
+		c := cli.NewContext(nil, set, nil)
+
+		require.Equal(t, want, filterChanges(c, changes, bugInstances))
+	}
+}
@@ -0,0 +1,143 @@
+// Package filter reads, validates and merges SpotBugs filter files, which select the bugs reported by SpotBugs.
+// See https://spotbugs.readthedocs.io/en/latest/filter.html
+package filter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// matchers are the elements a Match element can be made of, nested in Or, And and Not elements.
+var matchers = map[string]bool{
+	"And":        true,
+	"Bug":        true,
+	"BugCode":    true,
+	"BugPattern": true,
+	"Class":      true,
+	"Confidence": true,
+	"Field":      true,
+	"Local":      true,
+	"Method":     true,
+	"Not":        true,
+	"Or":         true,
+	"Package":    true,
+	"Priority":   true,
+	"Rank":       true,
+	"Source":     true,
+	"Type":       true,
+}
+
+// Filter is a SpotBugs filter file. A bug matches the filter if it matches any of its Match elements.
+type Filter struct {
+	XMLName xml.Name `xml:"FindBugsFilter"`
+	Comment string   `xml:",comment"`
+	Matches []Match  `xml:"Match"`
+}
+
+// Match is a Match element of a filter, kept as is.
+type Match struct {
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",innerxml"`
+}
+
+// node is an element of a Match element, used to validate it.
+type node struct {
+	XMLName xml.Name
+	Nodes   []node `xml:",any"`
+}
+
+// Load reads and validates the filter file at the given path.
+func Load(path string) (*Filter, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter file %s: %v", path, err)
+	}
+
+	return f, nil
+}
+
+// Parse decodes and validates the content of a filter file.
+func Parse(content []byte) (*Filter, error) {
+	var f Filter
+	if err := xml.Unmarshal(content, &f); err != nil {
+		return nil, err
+	}
+
+	if len(f.Matches) == 0 {
+		return nil, fmt.Errorf("no Match element found")
+	}
+
+	for i, m := range f.Matches {
+		if err := m.validate(); err != nil {
+			return nil, fmt.Errorf("Match element %d: %v", i+1, err)
+		}
+	}
+
+	return &f, nil
+}
+
+// validate returns an error if the match is empty or made of unknown elements.
+func (m Match) validate() error {
+	var root node
+	if err := xml.Unmarshal([]byte("<Match>"+m.Content+"</Match>"), &root); err != nil {
+		return err
+	}
+
+	if len(root.Nodes) == 0 {
+		return fmt.Errorf("empty match")
+	}
+
+	return validateNodes(root.Nodes)
+}
+
+func validateNodes(nodes []node) error {
+	for _, n := range nodes {
+		if !matchers[n.XMLName.Local] {
+			return fmt.Errorf("unknown element %s", n.XMLName.Local)
+		}
+
+		if err := validateNodes(n.Nodes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Merge returns a filter matching the bugs matched by any of the given filters, whose sources are recorded in
+// a comment.
+func Merge(sources []string, filters ...*Filter) *Filter {
+	merged := &Filter{Comment: commentText(fmt.Sprintf(" Merged from %s ", strings.Join(sources, ", ")))}
+	for _, f := range filters {
+		merged.Matches = append(merged.Matches, f.Matches...)
+	}
+
+	return merged
+}
+
+// commentText returns the text with its double hyphens separated, since they can't appear in an XML comment.
+func commentText(text string) string {
+	for strings.Contains(text, "--") {
+		text = strings.ReplaceAll(text, "--", "- -")
+	}
+
+	return text
+}
+
+// Write writes the filter to the given path.
+func (f *Filter) Write(path string) error {
+	content, err := xml.MarshalIndent(f, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(content, '\n'), os.FileMode(0644))
+}
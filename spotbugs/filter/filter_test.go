@@ -0,0 +1,121 @@
+package filter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "Valid",
+			content: `<FindBugsFilter><Match><Bug category="SECURITY"/></Match><Match><Or><Class name="~.*Generated"/><Not><Package name="acme"/></Not></Or></Match></FindBugsFilter>`,
+		},
+		{
+			name:    "Namespace",
+			content: `<FindBugsFilter xmlns="https://github.com/spotbugs/filter/3.0.0"><Match><Bug pattern="PREDICTABLE_RANDOM"/></Match></FindBugsFilter>`,
+		},
+		{
+			name:    "Invalid XML",
+			content: `<FindBugsFilter><Match>`,
+			wantErr: "unexpected EOF",
+		},
+		{
+			name:    "Wrong root",
+			content: `<SuppressionFilter><Match><Bug category="SECURITY"/></Match></SuppressionFilter>`,
+			wantErr: "expected element type <FindBugsFilter>",
+		},
+		{
+			name:    "No match",
+			content: `<FindBugsFilter></FindBugsFilter>`,
+			wantErr: "no Match element found",
+		},
+		{
+			name:    "Empty match",
+			content: `<FindBugsFilter><Match></Match></FindBugsFilter>`,
+			wantErr: "Match element 1: empty match",
+		},
+		{
+			name:    "Unknown element",
+			content: `<FindBugsFilter><Match><Bug category="SECURITY"/></Match><Match><Or><Classes name="Foo"/></Or></Match></FindBugsFilter>`,
+			wantErr: "Match element 2: unknown element Classes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error %q but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	security, err := Parse([]byte(`<!-- Security only --><FindBugsFilter><Match><Bug category="SECURITY"/></Match></FindBugsFilter>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	performance, err := Parse([]byte(`<FindBugsFilter><Match><Bug category="PERFORMANCE"/><Class name="~acme\..*"/></Match></FindBugsFilter>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "include.xml")
+	if err := Merge([]string{"include.xml", "performance.xml"}, security, performance).Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<FindBugsFilter>
+    <!-- Merged from include.xml, performance.xml -->
+    <Match><Bug category="SECURITY"/></Match>
+    <Match><Bug category="PERFORMANCE"/><Class name="~acme\..*"/></Match>
+</FindBugsFilter>
+`
+	if string(content) != want {
+		t.Errorf("Wrong merged filter. Expected:\n%s\nbut got:\n%s", want, content)
+	}
+
+	// The merged filter is a valid filter.
+	if _, err := Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Double hyphens of the sources aren't allowed in the comment.
+	path = filepath.Join(dir, "exclude.xml")
+	if err := Merge([]string{"config/spotbugs--exclude.xml", "config/a---b.xml"}, security).Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := " Merged from config/spotbugs- -exclude.xml, config/a- - -b.xml "; filter.Comment != want {
+		t.Errorf("Wrong comment. Expected %q but got %q", want, filter.Comment)
+	}
+}
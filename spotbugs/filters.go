@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/filter"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+// resolveFilters validates the user filter files and makes their paths absolute, relative to the repository
+// unless they already are.
+func resolveFilters(c *cli.Context, repositoryPath string) error {
+	for _, name := range []string{flagIncludeFilters, flagExcludeFilters} {
+		paths := utils.SplitList(c.String(name))
+		if len(paths) == 0 {
+			continue
+		}
+
+		for i, path := range paths {
+			if !filepath.IsAbs(path) {
+				paths[i] = filepath.Join(repositoryPath, path)
+			}
+
+			if _, err := filter.Load(paths[i]); err != nil {
+				log.Errorf("Error: %s\n", err.Error())
+				return err
+			}
+		}
+
+		if err := c.Set(name, strings.Join(paths, ",")); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Applying include filters %s and exclude filters %s.\n",
+		strings.Join(includeFilters(c), ", "), strings.Join(excludeFilters(c), ", "))
+	return nil
+}
+
+// includeFilters returns the built-in include filter followed by the user ones. A bug is reported if it matches
+// any of them.
+func includeFilters(c *cli.Context) []string {
+	return append([]string{pathInclude}, utils.SplitList(c.String(flagIncludeFilters))...)
+}
+
+// excludeFilters returns the built-in exclude filter followed by the user ones. A bug isn't reported if it
+// matches any of them.
+func excludeFilters(c *cli.Context) []string {
+	return append([]string{pathExclude}, utils.SplitList(c.String(flagExcludeFilters))...)
+}
+
+// writeFilters merges the include and exclude filters into the workspace. SpotBugs only reports the bugs matching
+// all of its include filters, so they can't be passed separately.
+func writeFilters(c *cli.Context, ws *workspace) error {
+	if err := mergeFilters(includeFilters(c), ws.IncludeFilter()); err != nil {
+		return err
+	}
+
+	return mergeFilters(excludeFilters(c), ws.ExcludeFilter())
+}
+
+// mergeFilters writes a filter merging the filter files at the given paths.
+func mergeFilters(paths []string, output string) error {
+	filters := make([]*filter.Filter, len(paths))
+	for i, path := range paths {
+		f, err := filter.Load(path)
+		if err != nil {
+			return err
+		}
+		filters[i] = f
+	}
+
+	return filter.Merge(paths, filters...).Write(output)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+const performanceFilter = `<FindBugsFilter><Match><Bug category="PERFORMANCE"/></Match></FindBugsFilter>`
+
+func TestResolveFilters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repository")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "config"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config", "performance.xml"), []byte(performanceFilter), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config", "invalid.xml"), []byte("<FindBugsFilter/>"), 0644))
+
+	newContext := func(include, exclude string) *cli.Context {
+		set := flag.NewFlagSet("analyze", 0)
+		set.String(flagIncludeFilters, include, "")
+		set.String(flagExcludeFilters, exclude, "")
+		return cli.NewContext(nil, set, nil)
+	}
+
+	c := newContext("config/performance.xml", "")
+	require.NoError(t, resolveFilters(c, dir))
+	require.Equal(t, []string{pathInclude, filepath.Join(dir, "config", "performance.xml")}, includeFilters(c))
+	require.Equal(t, []string{pathExclude}, excludeFilters(c))
+
+	require.Error(t, resolveFilters(newContext("", "config/invalid.xml"), dir))
+	require.Error(t, resolveFilters(newContext("config/missing.xml", ""), dir))
+}
+
+func TestMergeFilters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filters")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	performance := filepath.Join(dir, "performance.xml")
+	require.NoError(t, ioutil.WriteFile(performance, []byte(performanceFilter), 0644))
+
+	// The built-in filters are valid and merged with the user ones.
+	output := filepath.Join(dir, "include.xml")
+	require.NoError(t, mergeFilters([]string{"include.xml", performance}, output))
+
+	got, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+	require.Contains(t, string(got), `<Bug category="SECURITY"/>`)
+	require.Contains(t, string(got), `<Bug category="PERFORMANCE"/>`)
+	require.Contains(t, string(got), "Merged from include.xml, "+performance)
+
+	require.NoError(t, mergeFilters([]string{filepath.Join("spotbugs", "exclude.xml")}, filepath.Join(dir, "exclude.xml")))
+}
@@ -0,0 +1,161 @@
+// Package gitdiff computes the source files and lines changed since a git reference, using the local git repository.
+// It's used to limit the analysis to the code changed by a merge request.
+package gitdiff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+// SourceExtensions are the extensions of the source files taken into account.
+var SourceExtensions = []string{".java", ".groovy", ".scala", ".kt", ".kts"}
+
+var hunkMatcher = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// lineRange is a range of lines, 1-based and inclusive.
+type lineRange struct {
+	start int
+	end   int
+}
+
+// Changes lists the changed source files, relative to the directory where the diff was computed,
+// with their added or modified lines.
+type Changes struct {
+	files map[string][]lineRange
+}
+
+// Compute runs git diff in the given directory, comparing the working tree to the merge base of HEAD and the base
+// reference, which can be a branch, a tag or a commit SHA. Changes made to the base reference after HEAD forked
+// from it aren't taken into account.
+func Compute(path, base string) (*Changes, error) {
+	forkPoint, err := mergeBase(path, base)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"diff", "--relative", "--no-color", "--no-ext-diff", "--unified=0", "--diff-filter=ACMR", forkPoint, "--"}
+	for _, ext := range SourceExtensions {
+		args = append(args, "*"+ext)
+	}
+
+	cmd := utils.SetupCmdNoStd(path, exec.Command("git", args...))
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("git diff against %s failed: %s", base, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	log.Debugf("%s\n%s", cmd.String(), output)
+
+	return Parse(strings.NewReader(string(output)))
+}
+
+// mergeBase returns the commit SHA of the best common ancestor of HEAD and the base reference.
+func mergeBase(path, base string) (string, error) {
+	cmd := utils.SetupCmdNoStd(path, exec.Command("git", "merge-base", base, "HEAD"))
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git merge-base of %s and HEAD failed: %s", base, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Parse reads a unified diff produced with --unified=0.
+func Parse(r io.Reader) (*Changes, error) {
+	c := &Changes{files: make(map[string][]lineRange)}
+
+	var current, previous string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		header := strings.HasPrefix(previous, "--- ")
+		previous = line
+
+		switch {
+		// File headers always follow the "--- " line, unlike added lines starting with "++".
+		case header && strings.HasPrefix(line, "+++ "):
+			current = ""
+			target := strings.TrimPrefix(line, "+++ ")
+			if target == "/dev/null" {
+				continue
+			}
+			current = filepath.FromSlash(strings.TrimPrefix(unquote(target), "b/"))
+			if _, ok := c.files[current]; !ok {
+				c.files[current] = nil
+			}
+
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			match := hunkMatcher.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("malformed hunk header: %s", line)
+			}
+
+			start, _ := strconv.Atoi(match[1])
+			count := 1
+			if match[2] != "" {
+				count, _ = strconv.Atoi(match[2])
+			}
+
+			// Hunks with no new line only remove lines.
+			if count > 0 {
+				c.files[current] = append(c.files[current], lineRange{start: start, end: start + count - 1})
+			}
+		}
+	}
+
+	return c, scanner.Err()
+}
+
+// Files returns the changed files.
+func (c *Changes) Files() []string {
+	files := make([]string, 0, len(c.files))
+	for f := range c.files {
+		files = append(files, f)
+	}
+	return files
+}
+
+// HasFile returns true if the file has changed.
+func (c *Changes) HasFile(path string) bool {
+	_, ok := c.files[filepath.Clean(path)]
+	return ok
+}
+
+// HasLines returns true if any line from start to end (1-based, inclusive) of the file has changed.
+func (c *Changes) HasLines(path string, start, end int) bool {
+	if end < start {
+		end = start
+	}
+
+	for _, r := range c.files[filepath.Clean(path)] {
+		if start <= r.end && r.start <= end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unquote removes the quotes git adds around paths containing special characters.
+func unquote(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
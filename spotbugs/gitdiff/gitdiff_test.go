@@ -0,0 +1,141 @@
+package gitdiff
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const diff = `diff --git a/app/src/main/java/App.java b/app/src/main/java/App.java
+index 3b18e51..a2c4f0e 100644
+--- a/app/src/main/java/App.java
++++ b/app/src/main/java/App.java
+@@ -10,0 +11,2 @@ public class App {
++        Random r = new Random();
++        return r.nextLong();
+@@ -20 +22 @@ public class App {
+-        old();
++        updated();
+@@ -30,3 +32,0 @@ public class App {
+-        removed();
+diff --git a/lib/Removed.java b/lib/Removed.java
+deleted file mode 100644
+--- a/lib/Removed.java
++++ /dev/null
+@@ -1,3 +0,0 @@
+-class Removed {}
+diff --git a/lib/New.java b/lib/New.java
+new file mode 100644
+--- /dev/null
++++ b/lib/New.java
+@@ -0,0 +1,3 @@
++class New {
++}
++
+`
+
+func TestParse(t *testing.T) {
+	changes, err := Parse(strings.NewReader(diff))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := changes.Files()
+	sort.Strings(files)
+	want := []string{filepath.Join("app", "src", "main", "java", "App.java"), filepath.Join("lib", "New.java")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Changes.Files() = %v, want %v", files, want)
+	}
+
+	app := filepath.Join("app", "src", "main", "java", "App.java")
+	tests := []struct {
+		start int
+		end   int
+		want  bool
+	}{
+		{start: 11, end: 11, want: true},
+		{start: 9, end: 10, want: false},
+		{start: 5, end: 30, want: true},
+		{start: 22, end: 0, want: true},
+		{start: 23, end: 40, want: false},
+	}
+	for _, tt := range tests {
+		if got := changes.HasLines(app, tt.start, tt.end); got != tt.want {
+			t.Errorf("Changes.HasLines(%d, %d) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+
+	if changes.HasFile(filepath.Join("lib", "Removed.java")) {
+		t.Errorf("Changes.HasFile() = true for a removed file")
+	}
+}
+
+func TestCompute(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("App.java", "class App {\n}\n")
+	write("README.md", "readme\n")
+	write("Util.java", "class Util {\n}\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+
+	write("App.java", "class App {\n  void run() {}\n}\n")
+	write("README.md", "changed\n")
+	git("commit", "-q", "-a", "-m", "change")
+
+	changes, err := Compute(dir, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := changes.Files(); !reflect.DeepEqual(got, []string{"App.java"}) {
+		t.Errorf("Changes.Files() = %v, want [App.java]", got)
+	}
+
+	if !changes.HasLines("App.java", 2, 2) || changes.HasLines("App.java", 1, 1) {
+		t.Errorf("Changes.HasLines() doesn't match the changed line")
+	}
+
+	// Changes made to the base branch after the fork aren't changes of HEAD.
+	git("branch", "-q", "target", "HEAD~1")
+	git("checkout", "-q", "target")
+	write("Util.java", "class Util {\n  void help() {}\n}\n")
+	git("commit", "-q", "-a", "-m", "target change")
+	git("checkout", "-q", "-")
+
+	changes, err = Compute(dir, "target")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := changes.Files(); !reflect.DeepEqual(got, []string{"App.java"}) {
+		t.Errorf("Changes.Files() = %v, want [App.java]", got)
+	}
+
+	if _, err := Compute(dir, "unknown-ref"); err == nil {
+		t.Errorf("Compute() didn't fail on an unknown reference")
+	}
+}
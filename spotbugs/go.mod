@@ -7,6 +7,7 @@ require (
 	github.com/termie/go-shutil v0.0.0-20140729215957-bcacb06fecae
 	github.com/urfave/cli v1.22.1
 	gitlab.com/gitlab-org/security-products/analyzers/common/v2 v2.15.0
+	gopkg.in/yaml.v2 v2.3.0
 )
 
 go 1.13
@@ -0,0 +1,91 @@
+package instance
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/issue"
+)
+
+// BugPattern maps to the description of a bug type in the SpotBugs report.
+// It's only present when SpotBugs is run with the -xml:withMessages option.
+type BugPattern struct {
+	Type             string `xml:"type,attr"`
+	Abbrev           string `xml:"abbrev,attr"`
+	Category         string `xml:"category,attr"`
+	CWEID            int    `xml:"cweid,attr"`
+	ShortDescription string `xml:"ShortDescription"`
+	Details          string `xml:"Details"` // HTML description of the bug type
+}
+
+var (
+	solutionMatcher   = regexp.MustCompile(`(?is)<b>\s*solutions?\s*:?\s*</b>\s*:?(.*?)(?:<b>\s*references?\s*:?\s*</b>|$)`)
+	referencesMatcher = regexp.MustCompile(`(?is)<b>\s*references?\s*:?\s*</b>(.*)$`)
+	linkMatcher       = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+	unsafeMatcher     = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	lineBreakMatcher  = regexp.MustCompile(`(?i)<br\s*/?>|</?(p|pre|ul|ol|li|div)(\s[^>]*)?>`)
+	tagMatcher        = regexp.MustCompile(`(?s)<[^>]*>`)
+	spacesMatcher     = regexp.MustCompile(`\s+`)
+)
+
+// Solution returns the remediation advice found in the details of the bug pattern, as plain text.
+// It returns an empty string if the details don't contain a solution.
+func (p BugPattern) Solution() string {
+	match := solutionMatcher.FindStringSubmatch(p.Details)
+	if len(match) < 2 {
+		return ""
+	}
+
+	return sanitizeHTML(match[1])
+}
+
+// Links returns the reference URLs found in the details of the bug pattern, without duplicates.
+// Only the references section is used when it exists, so that footnote links don't appear twice.
+func (p BugPattern) Links() []issue.Link {
+	details := p.Details
+	if match := referencesMatcher.FindStringSubmatch(details); len(match) > 1 {
+		details = match[1]
+	}
+
+	var links []issue.Link
+	seen := make(map[string]bool)
+	for _, match := range linkMatcher.FindAllStringSubmatch(details, -1) {
+		url := strings.TrimSpace(html.UnescapeString(match[1]))
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			continue
+		}
+
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		links = append(links, issue.Link{
+			Name: strings.Join(strings.Fields(sanitizeHTML(match[2])), " "),
+			URL:  url,
+		})
+	}
+
+	return links
+}
+
+// sanitizeHTML converts an HTML fragment to plain text: scripts and tags are removed, entities are unescaped,
+// and whitespace is collapsed as a browser would, while keeping the line breaks of paragraphs, lists and code blocks.
+func sanitizeHTML(fragment string) string {
+	text := unsafeMatcher.ReplaceAllString(fragment, "")
+	text = spacesMatcher.ReplaceAllString(text, " ")
+	text = lineBreakMatcher.ReplaceAllString(text, "\n")
+	text = tagMatcher.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
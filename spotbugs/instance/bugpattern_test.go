@@ -0,0 +1,99 @@
+package instance
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/issue"
+)
+
+func TestBugPattern_Solution(t *testing.T) {
+	tests := []struct {
+		name    string
+		details string
+		want    string
+	}{
+		{
+			name: "Solution and references",
+			details: `<p>Vulnerable code.</p><p><b>Solution:</b><br/>Use <code>SecureRandom</code>
+				&amp; rotate   keys.<pre>SecureRandom r = new SecureRandom();</pre></p>
+				<p><b>References</b><br/><a href="https://example.com/ref">Ref</a></p>`,
+			want: "Use SecureRandom & rotate keys.\nSecureRandom r = new SecureRandom();",
+		},
+		{
+			name:    "Solution without references",
+			details: `<p><b>Solutions</b>: validate the input.<script>alert(1)</script></p>`,
+			want:    "validate the input.",
+		},
+		{
+			name:    "No solution",
+			details: `<p>Description only.</p>`,
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BugPattern{Details: tt.details}.Solution()
+			if got != tt.want {
+				t.Errorf("BugPattern.Solution() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBugPattern_Links(t *testing.T) {
+	tests := []struct {
+		name    string
+		details string
+		want    []issue.Link
+	}{
+		{
+			name: "References section",
+			details: `<p>See <sup><a href="https://example.com/footnote">[1]</a></sup>.</p>
+				<p><b>References</b><br/>
+				<a href="https://example.com/footnote">Footnote
+				article</a><br/>
+				<a href='http://cwe.mitre.org/data/definitions/330.html'>CWE-330</a><br/>
+				<a href="https://example.com/footnote">Duplicate</a>
+				<a href="mailto:security@example.com">Mail</a></p>`,
+			want: []issue.Link{
+				{Name: "Footnote article", URL: "https://example.com/footnote"},
+				{Name: "CWE-330", URL: "http://cwe.mitre.org/data/definitions/330.html"},
+			},
+		},
+		{
+			name:    "No references section",
+			details: `<p>See <a href="https://example.com/a?x=1&amp;y=2">the <b>doc</b></a>.</p>`,
+			want: []issue.Link{
+				{Name: "the doc", URL: "https://example.com/a?x=1&y=2"},
+			},
+		},
+		{
+			name:    "No links",
+			details: `<p>Nothing.</p>`,
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BugPattern{Details: tt.details}.Links()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BugPattern.Links() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstances_AddBugPatterns(t *testing.T) {
+	instances := Instances{BugPatterns: []BugPattern{{Type: "A", Details: "first"}}}
+	instances.AddBugPatterns([]BugPattern{{Type: "A", Details: "second"}, {Type: "B"}})
+
+	want := []BugPattern{{Type: "A", Details: "first"}, {Type: "B"}}
+	if !reflect.DeepEqual(instances.BugPatterns, want) {
+		t.Errorf("Instances.AddBugPatterns() = %#v, want %#v", instances.BugPatterns, want)
+	}
+
+	if _, ok := instances.BugPattern("C"); ok {
+		t.Errorf("Instances.BugPattern() found a missing bug type")
+	}
+}
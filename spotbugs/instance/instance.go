@@ -2,6 +2,8 @@
 package instance
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -14,7 +16,28 @@ import (
 
 // Instances maps to SpotBugs reports' root XML element.
 type Instances struct {
-	Instances []Instance `xml:"BugInstance"`
+	Instances   []Instance   `xml:"BugInstance"`
+	BugPatterns []BugPattern `xml:"BugPattern"`
+}
+
+// AddBugPatterns adds the bug patterns whose type isn't already present.
+func (i *Instances) AddBugPatterns(patterns []BugPattern) {
+	for _, pattern := range patterns {
+		if _, ok := i.BugPattern(pattern.Type); !ok {
+			i.BugPatterns = append(i.BugPatterns, pattern)
+		}
+	}
+}
+
+// BugPattern returns the bug pattern of the given bug type.
+func (i *Instances) BugPattern(bugType string) (BugPattern, bool) {
+	for _, pattern := range i.BugPatterns {
+		if pattern.Type == bugType {
+			return pattern, true
+		}
+	}
+
+	return BugPattern{}, false
 }
 
 // Instance maps to a bug - in our case a vulnerability - in the SpotBugs report.
@@ -33,7 +56,10 @@ type Instance struct {
 	Method struct {
 		Name string `xml:"name,attr"`
 	} `xml:"Method"`
-	SourceLine SourceLine `xml:"SourceLine"` // explicit SourceLine type annotation required to make XML marshaling work
+	SourceLine    SourceLine `xml:"SourceLine"`                   // explicit SourceLine type annotation required to make XML marshaling work
+	Suppressed    bool       `xml:"suppressed,attr,omitempty"`    // set by the analyzer when the issue is in the baseline or suppressed in the source
+	SourceHash    string     `xml:"sourceHash,attr,omitempty"`    // set by the analyzer, see HashSources
+	Justification string     `xml:"justification,attr,omitempty"` // set by the analyzer, see SuppressInSource
 }
 
 // SourceLine maps to a location of a vulnerability (source code file, start line, end line) in the SpotBugs report.
@@ -44,6 +70,8 @@ type SourceLine struct {
 }
 
 const (
+	trackingKeyIdentifierType = "spotbugs_tracking_key"
+
 	spotBugsURL    = "https://spotbugs.readthedocs.io/en/latest/bugDescriptions.html#"
 	findSecBugsURL = "https://find-sec-bugs.github.io/bugs.htm#"
 )
@@ -90,6 +118,26 @@ func (bug Instance) CompareKey() string {
 	return key
 }
 
+// TrackingKey returns a hash identifying the issue by its type, class, method, file and the content of the
+// source lines around it. Unlike CompareKey, it doesn't change when lines are added or removed elsewhere in the file.
+// It returns an empty string if the source lines haven't been hashed.
+func (bug Instance) TrackingKey() string {
+	if bug.SourceHash == "" {
+		return ""
+	}
+
+	fields := []string{
+		bug.Type,
+		bug.Class.Name,
+		bug.Method.Name,
+		bug.SourceLine.SourcePath,
+		bug.SourceHash,
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, ":")))
+	return hex.EncodeToString(sum[:])
+}
+
 // Severity returns the normalized Severity of the issue.
 // See https://github.com/spotbugs/spotbugs/blob/3.1.1/spotbugs/src/main/java/edu/umd/cs/findbugs/BugRankCategory.java#L32
 func (bug Instance) Severity() issue.SeverityLevel {
@@ -146,6 +194,15 @@ func (bug Instance) Identifiers() []issue.Identifier {
 		identifiers = append(identifiers, issue.CWEIdentifier(bug.CWEID))
 	}
 
+	// Add tracking key
+	if key := bug.TrackingKey(); key != "" {
+		identifiers = append(identifiers, issue.Identifier{
+			Type:  trackingKeyIdentifierType,
+			Name:  "SpotBugs tracking key",
+			Value: key,
+		})
+	}
+
 	return identifiers
 }
 
@@ -185,7 +242,7 @@ func (by By) Sort(bugInstances []Instance) {
 		bugInstances: bugInstances,
 		by:           by,
 	}
-	sort.Sort(bs)
+	sort.Stable(bs)
 }
 
 type bugInstanceSorter struct {
@@ -189,3 +189,28 @@ func TestBugInstance_Identifiers(t *testing.T) {
 		})
 	}
 }
+
+func TestBugInstance_TrackingKey(t *testing.T) {
+	bug := Instance{Type: "PREDICTABLE_RANDOM"}
+	bug.Class.Name = "com.gitlab.App"
+	bug.Method.Name = "token"
+	bug.SourceLine = SourceLine{Start: 29, End: 29, SourcePath: "src/main/java/com/gitlab/App.java"}
+
+	if key := bug.TrackingKey(); key != "" {
+		t.Errorf("TrackingKey() = %s without source hash, want empty key", key)
+	}
+
+	bug.SourceHash = "abcdef"
+	shifted := bug
+	shifted.SourceLine.Start, shifted.SourceLine.End = 31, 31
+
+	if bug.TrackingKey() == "" || bug.TrackingKey() != shifted.TrackingKey() {
+		t.Errorf("TrackingKey() changed when lines were shifted")
+	}
+
+	identifiers := bug.Identifiers()
+	last := identifiers[len(identifiers)-1]
+	if last.Type != "spotbugs_tracking_key" || last.Value != bug.TrackingKey() {
+		t.Errorf("Identifiers() doesn't end with the tracking key: %#v", last)
+	}
+}
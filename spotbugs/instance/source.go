@@ -0,0 +1,109 @@
+package instance
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sourceContextLines is the number of non blank lines around the issue included in the source hash.
+const sourceContextLines = 1
+
+// HashSources sets the SourceHash of each bug instance, reading source files relative to the repository path.
+// Bug instances whose source file can't be read keep an empty SourceHash.
+func HashSources(repositoryPath string, bugInstances []Instance) {
+	files := make(map[string][]string)
+
+	for i := range bugInstances {
+		bug := &bugInstances[i]
+		if bug.SourceLine.SourcePath == "" || bug.SourceLine.Start <= 0 {
+			continue
+		}
+
+		lines, ok := files[bug.SourceLine.SourcePath]
+		if !ok {
+			lines, _ = readLines(filepath.Join(repositoryPath, bug.SourceLine.SourcePath))
+			files[bug.SourceLine.SourcePath] = lines
+		}
+
+		bug.SourceHash = hashSourceLines(lines, bug.SourceLine.Start, bug.SourceLine.End)
+	}
+}
+
+// hashSourceLines returns a hash of the lines from start to end (1-based, inclusive) and of the non blank lines
+// around them. Lines are normalized so that changes of indentation or spacing and blank lines don't change the hash.
+// It returns an empty string if the lines are out of range.
+func hashSourceLines(lines []string, start, end int) string {
+	if end < start {
+		end = start
+	}
+	if start < 1 || end > len(lines) {
+		return ""
+	}
+
+	var context []string
+	context = append(context, nonBlankLines(lines[:start-1], sourceContextLines, true)...)
+	for _, line := range lines[start-1 : end] {
+		if normalized := normalizeLine(line); normalized != "" {
+			context = append(context, normalized)
+		}
+	}
+	context = append(context, nonBlankLines(lines[end:], sourceContextLines, false)...)
+
+	sum := sha256.Sum256([]byte(strings.Join(context, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// nonBlankLines returns at most count normalized non blank lines, taken from the end of lines if last is true,
+// from the beginning otherwise. Lines are returned in their original order.
+func nonBlankLines(lines []string, count int, last bool) []string {
+	result := make([]string, 0, count)
+	for i := range lines {
+		line := lines[i]
+		if last {
+			line = lines[len(lines)-1-i]
+		}
+
+		if normalized := normalizeLine(line); normalized != "" {
+			result = append(result, normalized)
+		}
+
+		if len(result) == count {
+			break
+		}
+	}
+
+	if last {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+
+	return result
+}
+
+// normalizeLine collapses the whitespace of a line.
+func normalizeLine(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// readLines returns the lines of a file.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
@@ -0,0 +1,66 @@
+package instance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sourceFile = `package com.gitlab;
+
+public class App {
+    String token() {
+        Random r = new Random();
+        return Long.toHexString(r.nextLong());
+    }
+}
+`
+
+func TestHashSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "App.java"), []byte(sourceFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines added above the issue and changed indentation.
+	shifted := "// header\n\n" + strings.Replace(sourceFile, "        Random", "\tRandom", 1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "Shifted.java"), []byte(shifted), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The line following the issue changed.
+	changed := strings.Replace(sourceFile, "Long.toHexString", "Long.toString", 1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "Changed.java"), []byte(changed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bugInstances := []Instance{
+		{SourceLine: SourceLine{Start: 5, End: 5, SourcePath: "App.java"}},
+		{SourceLine: SourceLine{Start: 7, End: 7, SourcePath: "Shifted.java"}},
+		{SourceLine: SourceLine{Start: 5, End: 5, SourcePath: "Changed.java"}},
+		{SourceLine: SourceLine{Start: 5, End: 5, SourcePath: "Missing.java"}},
+		{SourceLine: SourceLine{Start: 50, End: 50, SourcePath: "App.java"}},
+	}
+
+	HashSources(dir, bugInstances)
+
+	if bugInstances[0].SourceHash == "" {
+		t.Fatal("HashSources() didn't hash App.java")
+	}
+	if bugInstances[0].SourceHash != bugInstances[1].SourceHash {
+		t.Error("HashSources() changed when lines were shifted or reindented")
+	}
+	if bugInstances[0].SourceHash == bugInstances[2].SourceHash {
+		t.Error("HashSources() didn't change when the surrounding lines changed")
+	}
+	if bugInstances[3].SourceHash != "" || bugInstances[4].SourceHash != "" {
+		t.Error("HashSources() hashed a missing file or line")
+	}
+}
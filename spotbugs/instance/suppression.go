@@ -0,0 +1,120 @@
+package instance
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// suppressionMarker matches a comment suppressing bug types, like
+// // spotbugs-ignore: SQL_INJECTION_JDBC the query is built from constants
+var suppressionMarker = regexp.MustCompile(
+	`(?://|/\*|^\s*\*)\s*spotbugs-ignore:\s*([A-Z0-9_]+(?:\s*,\s*[A-Z0-9_]+)*)(.*?)(?:\*/)?\s*$`)
+
+// Suppression is a comment of a source file suppressing the bug instances of the given types.
+type Suppression struct {
+	Path          string
+	Line          int
+	Types         []string
+	Justification string
+}
+
+// Matches returns true if the comment suppresses the bug instance.
+func (s Suppression) Matches(bug Instance) bool {
+	for _, t := range s.Types {
+		if t == bug.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SuppressInSource sets the Justification of the bug instances suppressed by a comment of their source file,
+// relative to the repository path. The comment is either on the first line of the bug instance or on the comment
+// and annotation lines right above it. It returns the matching comments ignored because they have no justification.
+func SuppressInSource(repositoryPath string, bugInstances []Instance) []Suppression {
+	files := make(map[string][]string)
+
+	var unjustified []Suppression
+	for i := range bugInstances {
+		bug := &bugInstances[i]
+		if bug.SourceLine.SourcePath == "" || bug.SourceLine.Start <= 0 {
+			continue
+		}
+
+		lines, ok := files[bug.SourceLine.SourcePath]
+		if !ok {
+			lines, _ = readLines(filepath.Join(repositoryPath, bug.SourceLine.SourcePath))
+			files[bug.SourceLine.SourcePath] = lines
+		}
+
+		for _, s := range suppressions(bug.SourceLine.SourcePath, lines, bug.SourceLine.Start) {
+			if !s.Matches(*bug) {
+				continue
+			}
+
+			if s.Justification == "" {
+				unjustified = append(unjustified, s)
+				continue
+			}
+
+			bug.Justification = s.Justification
+			break
+		}
+	}
+
+	return unjustified
+}
+
+// suppressions returns the suppression comments applying to the given line (1-based): on the line itself, then on
+// the comment and annotation lines right above it.
+func suppressions(path string, lines []string, line int) []Suppression {
+	if line > len(lines) {
+		return nil
+	}
+
+	var result []Suppression
+	for i := line - 1; i >= 0; i-- {
+		if i < line-1 && !isCommentOrAnnotation(lines[i]) {
+			break
+		}
+
+		if s, ok := parseSuppression(lines[i]); ok {
+			s.Path = path
+			s.Line = i + 1
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// parseSuppression returns the suppression comment of a line, if any.
+func parseSuppression(line string) (Suppression, bool) {
+	m := suppressionMarker.FindStringSubmatch(line)
+	if m == nil {
+		return Suppression{}, false
+	}
+
+	types := strings.Split(m[1], ",")
+	for i := range types {
+		types[i] = strings.TrimSpace(types[i])
+	}
+
+	// The justification can be separated from the types by a dash or a colon.
+	justification := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(m[2]), "-:"))
+
+	return Suppression{Types: types, Justification: justification}, true
+}
+
+func isCommentOrAnnotation(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "/*", "*", "@"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
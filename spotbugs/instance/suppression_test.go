@@ -0,0 +1,122 @@
+package instance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const suppressedSourceFile = `package com.gitlab;
+
+public class App {
+    // spotbugs-ignore: SQL_INJECTION_JDBC the query is built from constants
+    @Override
+    String query() {
+        return "SELECT * FROM users WHERE name = '" + NAME + "'";
+    }
+
+    String token() {
+        Random r = new Random(); // spotbugs-ignore: PREDICTABLE_RANDOM, WEAK_HASH - not used for security
+        return Long.toHexString(r.nextLong());
+    }
+
+    String path() {
+        // spotbugs-ignore: PATH_TRAVERSAL_IN
+        return new File(name).getPath();
+    }
+
+    // spotbugs-ignore: COMMAND_INJECTION the comment doesn't apply to the next statement
+
+    void run() {
+        Runtime.getRuntime().exec(command);
+    }
+}
+`
+
+func TestSuppressInSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "App.java"), []byte(suppressedSourceFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newBug := func(bugType, path string, line int) Instance {
+		return Instance{Type: bugType, SourceLine: SourceLine{Start: line, End: line, SourcePath: path}}
+	}
+
+	bugInstances := []Instance{
+		newBug("SQL_INJECTION_JDBC", "App.java", 6),
+		newBug("SQL_INJECTION_JDBC", "App.java", 7),
+		newBug("PREDICTABLE_RANDOM", "App.java", 11),
+		newBug("WEAK_HASH", "App.java", 11),
+		newBug("SQL_INJECTION_JDBC", "App.java", 11),
+		newBug("PATH_TRAVERSAL_IN", "App.java", 17),
+		newBug("COMMAND_INJECTION", "App.java", 22),
+		newBug("COMMAND_INJECTION", "Missing.java", 22),
+	}
+
+	unjustified := SuppressInSource(dir, bugInstances)
+
+	var got []string
+	for _, bug := range bugInstances {
+		got = append(got, bug.Justification)
+	}
+	want := []string{
+		"the query is built from constants",
+		"",
+		"not used for security",
+		"not used for security",
+		"",
+		"",
+		"",
+		"",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong justifications. Expected:\n%#v\nBut got:\n%#v", want, got)
+	}
+
+	wantUnjustified := []Suppression{{Path: "App.java", Line: 16, Types: []string{"PATH_TRAVERSAL_IN"}}}
+	if !reflect.DeepEqual(unjustified, wantUnjustified) {
+		t.Errorf("Wrong unjustified suppressions. Expected:\n%#v\nBut got:\n%#v", wantUnjustified, unjustified)
+	}
+}
+
+func TestParseSuppression(t *testing.T) {
+	tests := []struct {
+		line string
+		want Suppression
+		ok   bool
+	}{
+		{
+			line: "// spotbugs-ignore: SQL_INJECTION_JDBC reviewed",
+			want: Suppression{Types: []string{"SQL_INJECTION_JDBC"}, Justification: "reviewed"},
+			ok:   true,
+		},
+		{
+			line: "/* spotbugs-ignore: XSS_SERVLET,XSS_JSP_PRINT: escaped by the template */",
+			want: Suppression{Types: []string{"XSS_SERVLET", "XSS_JSP_PRINT"}, Justification: "escaped by the template"},
+			ok:   true,
+		},
+		{
+			line: " * spotbugs-ignore: WEAK_HASH",
+			want: Suppression{Types: []string{"WEAK_HASH"}},
+			ok:   true,
+		},
+		{
+			line: "String s = \"spotbugs-ignore: WEAK_HASH\";",
+		},
+	}
+
+	for _, test := range tests {
+		got, ok := parseSuppression(test.line)
+		if ok != test.ok || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseSuppression(%q) = %#v, %v, want %#v, %v", test.line, got, ok, test.want, test.ok)
+		}
+	}
+}
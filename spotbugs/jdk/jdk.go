@@ -0,0 +1,390 @@
+// Package jdk finds JDKs available locally, as unpacked directories or archives, so that projects can be built
+// and analyzed without downloading Java.
+package jdk
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// filenameRelease describes the version of a JDK, in its home directory.
+	filenameRelease = "release"
+
+	// maxHomeDepth is how deep the home directory of a JDK is searched in an unpacked archive, like
+	// jdk-17.0.9+9/Contents/Home on macOS.
+	maxHomeDepth = 3
+)
+
+// archiveExtensions are the extensions of the JDK archives that can be unpacked.
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+var (
+	// releaseVersion matches the Java version of a release file.
+	releaseVersion = regexp.MustCompile(`(?m)^JAVA_VERSION="?([^"\s]+)"?`)
+
+	// nameVersion matches the Java version in the name of a JDK directory or archive, like 17.0.9 in
+	// OpenJDK17U-jdk_x64_linux_hotspot_17.0.9_9.tar.gz or 1.8 in jdk1.8.0_382.
+	nameVersion = regexp.MustCompile(`[0-9]+(?:\.[0-9]+)*`)
+)
+
+// JDK is a JDK found locally.
+type JDK struct {
+	Major   int    // major Java version
+	Path    string // home directory of the JDK, or path of its archive
+	Archive bool   // the JDK must be unpacked before it's used
+}
+
+func (j JDK) String() string {
+	return fmt.Sprintf("Java %d (%s)", j.Major, j.Path)
+}
+
+// Discover returns the JDKs of a directory, sorted by major version: its sub-directories containing a JDK and the
+// JDK archives whose name contains the Java version.
+func Discover(dir string) ([]JDK, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var jdks []JDK
+	for _, info := range infos {
+		path := filepath.Join(dir, info.Name())
+		if info, err = followLink(path, info); err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			home, err := findHome(path, maxHomeDepth)
+			if err != nil {
+				return nil, err
+			}
+			if home == "" {
+				continue
+			}
+
+			if major := homeVersion(home); major > 0 {
+				jdks = append(jdks, JDK{Major: major, Path: home})
+			}
+			continue
+		}
+
+		if isArchive(info.Name()) {
+			if major := nameMajorVersion(info.Name()); major > 0 {
+				jdks = append(jdks, JDK{Major: major, Path: path, Archive: true})
+			}
+		}
+	}
+
+	sort.SliceStable(jdks, func(i, j int) bool { return jdks[i].Major < jdks[j].Major })
+
+	return jdks, nil
+}
+
+// Find returns the JDK of the given major version, or an error listing the available JDKs if there is none.
+func Find(jdks []JDK, major int) (JDK, error) {
+	for _, j := range jdks {
+		if j.Major == major {
+			return j, nil
+		}
+	}
+
+	return JDK{}, notFound(jdks, fmt.Sprintf("Java %d", major))
+}
+
+// Select returns the JDK of the lowest major version that is at least the required one, or an error listing
+// the available JDKs if none is.
+func Select(jdks []JDK, required int) (JDK, error) {
+	for _, j := range jdks {
+		if j.Major >= required {
+			return j, nil
+		}
+	}
+
+	return JDK{}, notFound(jdks, fmt.Sprintf("Java %d or newer", required))
+}
+
+func notFound(jdks []JDK, wanted string) error {
+	found := make([]string, len(jdks))
+	for i, j := range jdks {
+		found[i] = j.String()
+	}
+	if len(found) == 0 {
+		found = []string{"none"}
+	}
+
+	return fmt.Errorf("no JDK for %s, found: %s", wanted, strings.Join(found, ", "))
+}
+
+// Home returns the home directory of the JDK, unpacking its archive in the cache directory if needed. An archive
+// already unpacked in the cache directory isn't unpacked again.
+func (j JDK) Home(cacheDir string) (string, error) {
+	if !j.Archive {
+		return j.Path, nil
+	}
+
+	dest := filepath.Join(cacheDir, trimArchiveExtension(filepath.Base(j.Path)))
+	if home, err := findHome(dest, maxHomeDepth); err == nil && home != "" {
+		return home, nil
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return "", err
+	}
+	if err := unpack(j.Path, dest); err != nil {
+		return "", fmt.Errorf("couldn't unpack %s: %v", j.Path, err)
+	}
+
+	home, err := findHome(dest, maxHomeDepth)
+	if err != nil {
+		return "", err
+	}
+	if home == "" {
+		return "", fmt.Errorf("no JDK found in %s", j.Path)
+	}
+
+	return home, nil
+}
+
+// findHome returns the home directory of the JDK in a directory or its sub-directories, or an empty string if
+// there is none.
+func findHome(dir string, depth int) (string, error) {
+	if isHome(dir) {
+		return dir, nil
+	}
+	if depth == 0 {
+		return "", nil
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, info := range infos {
+		if info, err = followLink(filepath.Join(dir, info.Name()), info); err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			continue
+		}
+
+		home, err := findHome(filepath.Join(dir, info.Name()), depth-1)
+		if err != nil || home != "" {
+			return home, err
+		}
+	}
+
+	return "", nil
+}
+
+// followLink returns the file info of the target of a symbolic link, so that linked JDK directories are found,
+// or the file info itself if it isn't a link. Broken links are returned as is.
+func followLink(path string, info os.FileInfo) (os.FileInfo, error) {
+	if info.Mode()&os.ModeSymlink == 0 {
+		return info, nil
+	}
+
+	target, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return info, nil
+	}
+
+	return target, err
+}
+
+// isHome returns true if the directory is the home directory of a JDK.
+func isHome(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "bin", "java"))
+	return err == nil && !info.IsDir()
+}
+
+// homeVersion returns the major version of a JDK, read from its release file or its directory name.
+func homeVersion(home string) int {
+	f, err := os.Open(filepath.Join(home, filenameRelease))
+	if err != nil {
+		return nameMajorVersion(filepath.Base(home))
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if m := releaseVersion.FindStringSubmatch(scanner.Text()); m != nil {
+			return nameMajorVersion(m[1])
+		}
+	}
+
+	return nameMajorVersion(filepath.Base(home))
+}
+
+// nameMajorVersion returns the major version of the first Java version found in a name, like 8 for jdk1.8.0_382,
+// or 0 if there is none.
+func nameMajorVersion(name string) int {
+	parts := strings.Split(nameVersion.FindString(name), ".")
+	if parts[0] == "1" && len(parts) > 1 {
+		parts = parts[1:]
+	}
+
+	major, _ := strconv.Atoi(parts[0])
+	return major
+}
+
+func isArchive(name string) bool {
+	return trimArchiveExtension(name) != name
+}
+
+func trimArchiveExtension(name string) string {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+
+	return name
+}
+
+// unpack extracts a tar.gz or zip archive in a directory.
+func unpack(archivePath, dest string) error {
+	if strings.HasSuffix(archivePath, ".zip") {
+		return unzip(archivePath, dest)
+	}
+
+	return untar(archivePath, dest)
+}
+
+// target returns the path where an archive entry is extracted, rejecting the entries outside of the destination.
+func target(dest, name string) (string, error) {
+	path := filepath.Join(dest, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(dest, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid entry %s", name)
+	}
+
+	return path, nil
+}
+
+func untar(archivePath, dest string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	r := tar.NewReader(gz)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path, err := target(dest, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg, tar.TypeRegA:
+			err = writeFile(path, r, os.FileMode(header.Mode))
+		case tar.TypeSymlink:
+			// Links can't point outside of the destination.
+			if filepath.IsAbs(header.Linkname) {
+				err = fmt.Errorf("invalid link %s", header.Name)
+			} else if _, err = target(dest, filepath.Join(filepath.Dir(header.Name), header.Linkname)); err == nil {
+				err = symlink(header.Linkname, path)
+			}
+		case tar.TypeLink:
+			var oldname string
+			if oldname, err = target(dest, header.Linkname); err == nil {
+				err = os.Link(oldname, path)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func unzip(archivePath, dest string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		path, err := target(dest, f.Name)
+		if err != nil {
+			return err
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(path, src, f.Mode())
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes the content of an archive entry, keeping its permissions so that executables can be run.
+func writeFile(path string, content io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, content); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+func symlink(oldname, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.Symlink(oldname, path)
+}
@@ -0,0 +1,165 @@
+package jdk
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/testutil"
+)
+
+// writeTarGz writes a tar.gz archive containing the given files.
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	w := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jdks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		"temurin-11/bin/java":                               "",
+		"temurin-11/release":                                "IMPLEMENTOR=\"Eclipse Adoptium\"\nJAVA_VERSION=\"11.0.21\"\n",
+		"jdk1.8.0_382/bin/java":                             "",
+		"jdk-17.0.9+9/Contents/Home/bin/java":               "",
+		"jdk-17.0.9+9/Contents/Home/release":                "JAVA_VERSION=\"17.0.9\"\n",
+		"maven-3.6.3/bin/mvn":                               "",
+		"README.md":                                         "JDKs of the runners",
+		"OpenJDK21U-jdk_x64_linux_hotspot_21.0.1_12.tar.gz": "",
+	})
+
+	// JDKs can be linked from another directory, and broken links are ignored.
+	linked, err := ioutil.TempDir("", "zulu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(linked)
+
+	testutil.WriteFiles(t, linked, map[string]string{"bin/java": "", "release": "JAVA_VERSION=\"22.0.1\"\n"})
+	if err := os.Symlink(linked, filepath.Join(dir, "zulu-22")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "jdk-23")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []JDK{
+		{Major: 8, Path: filepath.Join(dir, "jdk1.8.0_382")},
+		{Major: 11, Path: filepath.Join(dir, "temurin-11")},
+		{Major: 17, Path: filepath.Join(dir, "jdk-17.0.9+9", "Contents", "Home")},
+		{Major: 21, Path: filepath.Join(dir, "OpenJDK21U-jdk_x64_linux_hotspot_21.0.1_12.tar.gz"), Archive: true},
+		{Major: 22, Path: filepath.Join(dir, "zulu-22")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong JDKs. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
+
+func TestFindAndSelect(t *testing.T) {
+	jdks := []JDK{{Major: 8, Path: "/opt/jdk8"}, {Major: 17, Path: "/opt/jdk17"}}
+
+	if got, err := Find(jdks, 17); err != nil || got.Path != "/opt/jdk17" {
+		t.Errorf("Expected /opt/jdk17 but got %v (%v)", got, err)
+	}
+	if got, err := Select(jdks, 11); err != nil || got.Path != "/opt/jdk17" {
+		t.Errorf("Expected /opt/jdk17 but got %v (%v)", got, err)
+	}
+
+	_, err := Find(jdks, 11)
+	if err == nil || !strings.Contains(err.Error(), "Java 8 (/opt/jdk8), Java 17 (/opt/jdk17)") {
+		t.Errorf("Expected an error listing the JDKs but got %v", err)
+	}
+	if _, err := Select(jdks, 21); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestHome(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jdks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "jdk-21.tar.gz")
+	writeTarGz(t, archive, map[string]string{
+		"jdk-21.0.1+12/bin/java": "#!/bin/sh\necho 21",
+		"jdk-21.0.1+12/release":  "JAVA_VERSION=\"21.0.1\"\n",
+	})
+
+	cacheDir := filepath.Join(dir, "cache")
+	j := JDK{Major: 21, Path: archive, Archive: true}
+	home, err := j.Home(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join(cacheDir, "jdk-21", "jdk-21.0.1+12"); home != want {
+		t.Errorf("Wrong home. Expected %s but got %s", want, home)
+	}
+	info, err := os.Stat(filepath.Join(home, "bin", "java"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&0100 == 0 {
+		t.Errorf("Expected java to be executable but got %s", info.Mode())
+	}
+
+	// The unpacked archive is reused.
+	if err := os.Remove(archive); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := j.Home(cacheDir); err != nil || again != home {
+		t.Errorf("Expected %s but got %s (%v)", home, again, err)
+	}
+}
+
+func TestUntar_InvalidEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jdks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "evil.tar.gz")
+	writeTarGz(t, archive, map[string]string{"../evil/bin/java": ""})
+
+	if err := untar(archive, filepath.Join(dir, "dest")); err == nil {
+		t.Error("Expected an error")
+	}
+}
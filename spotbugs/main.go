@@ -37,6 +37,7 @@ func main() {
 		Scanner:      metadata.ReportScanner,
 		ScanType:     metadata.Type,
 	})
+	app.Commands = append(app.Commands, baselineCommand())
 
 	if err := app.Run(os.Args); err != nil {
 		log.Fatal(err)
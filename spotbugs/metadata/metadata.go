@@ -28,12 +28,12 @@ const (
 
 var (
 	// AnalyzerVersion is the semantic version of the analyzer and must match the most recent version in CHANGELOG.md
-	AnalyzerVersion = "2.11.0"
+	AnalyzerVersion = "2.12.0"
 
 	// ScannerVersion is the semantic version of the scanner (bundler-audit)
 	// TODO: ensure this version matches the one specified in the Dockerfile
 	//       see https://gitlab.com/gitlab-org/gitlab/-/issues/235059
-	ScannerVersion = "4.0.2"
+	ScannerVersion = "4.8.3"
 
 	// IssueScanner describes the scanner used to find a vulnerability
 	IssueScanner = issue.Scanner{
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+const (
+	effortDefault  = "max"
+	minRank        = 1
+	maxRank        = 20
+	priorityLow    = "low"
+	priorityMedium = "medium"
+	priorityHigh   = "high"
+)
+
+// efforts are the SpotBugs effort levels, from the fastest to the most precise.
+var efforts = []string{"min", "less", "default", "more", "max"}
+
+// detectorName matches the name of a SpotBugs detector, like FindSqlInjection.
+var detectorName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedArgs are the SpotBugs options set by the analyzer, which can't be passed as extra arguments.
+var reservedArgs = []string{
+	"-auxclasspath", "-auxclasspathFromFile", "-output", "-outputFile", "-xml", "-html", "-sarif", "-emacs",
+	"-xdocs", "-onlyAnalyze", "-pluginList", "-exclude", "-include", "-effort", "-low", "-medium", "-high",
+	"-maxRank", "-chooseVisitors", "-visitors", "-omitVisitors",
+}
+
+// validateSpotBugsOptions returns an error if the effort, priority, rank, detectors or extra arguments of
+// SpotBugs are invalid.
+func validateSpotBugsOptions(c *cli.Context) error {
+	if effort := c.String(flagEffort); effort != "" && !utils.Contains(efforts, effort) {
+		return fmt.Errorf("effort %s is not supported. Valid values are %s", effort, strings.Join(efforts, ", "))
+	}
+
+	switch c.String(flagPriority) {
+	case "", priorityLow, priorityMedium, priorityHigh:
+	default:
+		return fmt.Errorf(
+			"priority %s is not supported. Valid values are %s, %s, %s",
+			c.String(flagPriority), priorityLow, priorityMedium, priorityHigh)
+	}
+
+	if rank := c.Int(flagMaxRank); rank != 0 && (rank < minRank || rank > maxRank) {
+		return fmt.Errorf("rank %d is not supported. Valid values are %d to %d", rank, minRank, maxRank)
+	}
+
+	enabled := utils.SplitList(c.String(flagEnableDetectors))
+	for _, detector := range append(enabled, utils.SplitList(c.String(flagDisableDetectors))...) {
+		if !detectorName.MatchString(detector) {
+			return fmt.Errorf("invalid detector name %s", detector)
+		}
+	}
+	for _, detector := range utils.SplitList(c.String(flagDisableDetectors)) {
+		if utils.Contains(enabled, detector) {
+			return fmt.Errorf("detector %s can't be both enabled and disabled", detector)
+		}
+	}
+
+	for _, arg := range strings.Fields(c.String(flagExtraArgs)) {
+		name := strings.SplitN(arg, ":", 2)[0]
+		if utils.Contains(reservedArgs, name) {
+			return fmt.Errorf("SpotBugs option %s is set by the analyzer and can't be passed as an extra argument", arg)
+		}
+	}
+
+	return nil
+}
+
+// spotBugsOptionArgs returns the SpotBugs arguments of the effort, priority, rank and detectors, followed by the
+// extra arguments.
+func spotBugsOptionArgs(c *cli.Context) []string {
+	args := []string{"-effort:" + effort(c), "-" + priority(c)}
+
+	if rank := c.Int(flagMaxRank); rank != 0 && rank != maxRank {
+		args = append(args, "-maxRank", strconv.Itoa(rank))
+	}
+
+	var visitors []string
+	for _, detector := range utils.SplitList(c.String(flagEnableDetectors)) {
+		visitors = append(visitors, "+"+detector)
+	}
+	for _, detector := range utils.SplitList(c.String(flagDisableDetectors)) {
+		visitors = append(visitors, "-"+detector)
+	}
+	if len(visitors) > 0 {
+		args = append(args, "-chooseVisitors", strings.Join(visitors, ","))
+	}
+
+	return append(args, strings.Fields(c.String(flagExtraArgs))...)
+}
+
+// scannerSettings returns the SpotBugs settings of the analysis, logged when it starts and reported as the
+// properties of the SARIF tool. The GitLab report has no field for them.
+func scannerSettings(c *cli.Context) map[string]string {
+	settings := map[string]string{
+		"effort":   effort(c),
+		"priority": priority(c),
+		"maxRank":  strconv.Itoa(maxRank),
+	}
+	if rank := c.Int(flagMaxRank); rank != 0 {
+		settings["maxRank"] = strconv.Itoa(rank)
+	}
+	if detectors := utils.SplitList(c.String(flagEnableDetectors)); len(detectors) > 0 {
+		settings["enabledDetectors"] = strings.Join(detectors, ",")
+	}
+	if detectors := utils.SplitList(c.String(flagDisableDetectors)); len(detectors) > 0 {
+		settings["disabledDetectors"] = strings.Join(detectors, ",")
+	}
+	if args := strings.Fields(c.String(flagExtraArgs)); len(args) > 0 {
+		settings["extraArgs"] = strings.Join(args, " ")
+	}
+	settings["includeFilters"] = strings.Join(includeFilters(c), ",")
+	settings["excludeFilters"] = strings.Join(excludeFilters(c), ",")
+	if f, err := pathFilter(c); err == nil {
+		if len(f.Excluded.ExcludedPaths) > 0 {
+			settings["excludedPaths"] = strings.Join(f.Excluded.ExcludedPaths, ",")
+		}
+		if len(f.TestPaths) > 0 {
+			settings["excludedTestPaths"] = strings.Join(f.TestPaths, ",")
+		}
+		if len(f.Included) > 0 {
+			settings["includedPaths"] = strings.Join(f.Included, ",")
+		}
+	}
+
+	return settings
+}
+
+// formatSettings returns the non empty settings as key=value pairs sorted by key, for the logs.
+func formatSettings(settings map[string]string) string {
+	var pairs []string
+	for key, value := range settings {
+		if value != "" {
+			pairs = append(pairs, key+"="+value)
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ", ")
+}
+
+func effort(c *cli.Context) string {
+	if effort := c.String(flagEffort); effort != "" {
+		return effort
+	}
+	return effortDefault
+}
+
+func priority(c *cli.Context) string {
+	if priority := c.String(flagPriority); priority != "" {
+		return priority
+	}
+	return priorityLow
+}
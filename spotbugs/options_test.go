@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func newOptionsContext(values map[string]string, rank int) *cli.Context {
+	set := flag.NewFlagSet("analyze", 0)
+	for _, name := range []string{flagEffort, flagPriority, flagEnableDetectors, flagDisableDetectors, flagExtraArgs} {
+		set.String(name, values[name], "")
+	}
+	set.Int(flagMaxRank, rank, "")
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestValidateSpotBugsOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]string
+		rank    int
+		wantErr bool
+	}{
+		{name: "Defaults"},
+		{name: "Valid", values: map[string]string{flagEffort: "less", flagPriority: "high", flagEnableDetectors: "FindSqlInjection"}, rank: 14},
+		{name: "Invalid effort", values: map[string]string{flagEffort: "maximum"}, wantErr: true},
+		{name: "Invalid priority", values: map[string]string{flagPriority: "critical"}, wantErr: true},
+		{name: "Invalid rank", rank: 21, wantErr: true},
+		{name: "Invalid detector", values: map[string]string{flagDisableDetectors: "Find-Bugs"}, wantErr: true},
+		{name: "Enabled and disabled detector", values: map[string]string{flagEnableDetectors: "A, B", flagDisableDetectors: "B"}, wantErr: true},
+		{name: "Extra argument", values: map[string]string{flagExtraArgs: "-nested:false -adjustPriority Foo=raise"}},
+		{name: "Reserved extra argument", values: map[string]string{flagExtraArgs: "-effort:min"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSpotBugsOptions(newOptionsContext(tt.values, tt.rank))
+			require.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+		})
+	}
+}
+
+func TestSpotBugsOptionArgs(t *testing.T) {
+	require.Equal(t, []string{"-effort:max", "-low"}, spotBugsOptionArgs(newOptionsContext(nil, maxRank)))
+
+	c := newOptionsContext(map[string]string{
+		flagEffort:           "less",
+		flagPriority:         "medium",
+		flagEnableDetectors:  "FindSqlInjection",
+		flagDisableDetectors: "FindDeadLocalStores, FindNullDeref",
+		flagExtraArgs:        " -nested:false ",
+	}, 9)
+	want := []string{
+		"-effort:less", "-medium", "-maxRank", "9",
+		"-chooseVisitors", "+FindSqlInjection,-FindDeadLocalStores,-FindNullDeref",
+		"-nested:false",
+	}
+	require.Equal(t, want, spotBugsOptionArgs(c))
+
+	require.Equal(t, map[string]string{
+		"effort":            "less",
+		"priority":          "medium",
+		"maxRank":           "9",
+		"enabledDetectors":  "FindSqlInjection",
+		"disabledDetectors": "FindDeadLocalStores,FindNullDeref",
+		"extraArgs":         "-nested:false",
+		"includeFilters":    "/spotbugs/include.xml",
+		"excludeFilters":    "/spotbugs/exclude.xml",
+	}, scannerSettings(c))
+}
+
+func TestFormatSettings(t *testing.T) {
+	settings := map[string]string{
+		"priority":       "low",
+		"effort":         "max",
+		"maxRank":        "20",
+		"includeFilters": "",
+	}
+	require.Equal(t, "effort=max, maxRank=20, priority=low", formatSettings(settings))
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/pathfilter"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+// defaultTestPaths are the test source directories of Maven, Gradle and SBT projects, not analyzed by default.
+const defaultTestPaths = "src/test,src/*Test,src/testFixtures"
+
+// pathFilter returns the filter of the analyzed paths: the paths of SAST_EXCLUDED_PATHS and the test sources aren't
+// analyzed and, if included paths are given, only the source files they match are. The test sources are analyzed by
+// default along with the test classes. The filter is logged with the scanner settings.
+func pathFilter(c *cli.Context) (*project.PathFilter, error) {
+	excluded, err := pathfilter.NewFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &project.PathFilter{
+		Excluded:  *excluded,
+		TestPaths: utils.SplitList(c.String(flagExcludeTestPaths)),
+		Included:  utils.SplitList(c.String(flagIncludePaths)),
+	}
+	if c.Bool(flagIncludeTests) && !c.IsSet(flagExcludeTestPaths) {
+		filter.TestPaths = nil
+	}
+
+	return filter, nil
+}
+
+// filterPaths drops the bug instances whose source file, relative to the repository, is excluded by the path filter.
+// The run command filters the GitLab report with SAST_EXCLUDED_PATHS too, but not the SARIF report and the baseline.
+func filterPaths(filter *project.PathFilter, bugInstances []instance.Instance) []instance.Instance {
+	if filter.IsEmpty() {
+		return bugInstances
+	}
+
+	result := make([]instance.Instance, 0, len(bugInstances))
+	for _, bug := range bugInstances {
+		if filter.IsExcluded(bug.SourceLine.SourcePath) {
+			continue
+		}
+		result = append(result, bug)
+	}
+
+	if dropped := len(bugInstances) - len(result); dropped > 0 {
+		log.Infof("%d of %d findings are in excluded paths\n", dropped, len(bugInstances))
+	}
+	return result
+}
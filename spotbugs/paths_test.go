@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/pathfilter"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+)
+
+// newPathContext returns the context of the path filter flags, with the given arguments.
+func newPathContext(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("analyze", 0)
+	for _, f := range append(analyzeFlags(), pathfilter.MakeFlags("SAST_")...) {
+		f.Apply(set)
+	}
+	require.NoError(t, set.Parse(args))
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestFilterPaths(t *testing.T) {
+	newBug := func(path string) instance.Instance {
+		bug := instance.Instance{Type: "PREDICTABLE_RANDOM"}
+		bug.SourceLine.SourcePath = path
+		return bug
+	}
+
+	bugs := []instance.Instance{
+		newBug("api/src/main/java/App.java"),
+		newBug("api/src/test/java/AppTest.java"),
+		newBug("third_party/src/main/java/Lib.java"),
+		newBug("tools/src/main/java/Tool.java"),
+	}
+
+	filter := &project.PathFilter{
+		Excluded:  pathfilter.Filter{ExcludedPaths: []string{"third_party"}},
+		TestPaths: []string{"src/test"},
+		Included:  []string{"api", "third_party"},
+	}
+	require.Equal(t, bugs[:1], filterPaths(filter, bugs))
+
+	// Nothing is dropped without excluded paths.
+	require.Equal(t, bugs, filterPaths(&project.PathFilter{}, bugs))
+}
+
+func TestPathFilter(t *testing.T) {
+	// The test sources are excluded by default, on top of the excluded paths.
+	f, err := pathFilter(newPathContext(t, "-excluded-paths", "third_party"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"third_party"}, f.Excluded.ExcludedPaths)
+	require.Equal(t, []string{"src/test", "src/*Test", "src/testFixtures"}, f.TestPaths)
+	require.Empty(t, f.Included)
+
+	// They are analyzed along with the test classes, unless the test paths are set.
+	f, err = pathFilter(newPathContext(t, "-"+flagIncludeTests, "-"+flagIncludePaths, "api, web"))
+	require.NoError(t, err)
+	require.Empty(t, f.TestPaths)
+	require.Equal(t, []string{"api", "web"}, f.Included)
+
+	f, err = pathFilter(newPathContext(t, "-"+flagIncludeTests, "-"+flagExcludeTestPaths, "src/it"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"src/it"}, f.TestPaths)
+}
+
+func TestScannerSettings_ExcludedPaths(t *testing.T) {
+	c := newPathContext(t, "-excluded-paths", "test,third_party", "-"+flagIncludePaths, "api")
+
+	// The path filter is recorded in the settings of the scanner.
+	settings := scannerSettings(c)
+	require.Equal(t, "test,third_party", settings["excludedPaths"])
+	require.Equal(t, defaultTestPaths, settings["excludedTestPaths"])
+	require.Equal(t, "api", settings["includedPaths"])
+}
@@ -2,20 +2,50 @@ package plugin
 
 import (
 	"os"
+	"path/filepath"
 
 	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/plugin"
 
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/artifact"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/config"
 	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
 )
 
 var isMatchingDone = false
 var isMatching = false
 
-// Match checks if this project can be built by one of our supported builders:
+// envArtifacts is the environment variable enabling the analysis of prebuilt archives.
+const envArtifacts = "SPOTBUGS_ARTIFACTS"
+
+// envBuildCommand is the environment variable setting a custom build command, for projects without build file.
+const envBuildCommand = "SPOTBUGS_BUILD_COMMAND"
+
+// envConfigFile is the environment variable setting the configuration file, which can set custom build commands.
+const envConfigFile = "SPOTBUGS_CONFIG_FILE"
+
+// Match checks if this project can be built by one of our supported builders, by a custom build command,
+// or if the file is a prebuilt archive that can be analyzed.
 func Match(path string, info os.FileInfo) (bool, error) {
+	if os.Getenv(envArtifacts) != "" && artifact.IsArchive(info.Name()) {
+		return true, nil
+	}
+
+	if os.Getenv(envBuildCommand) != "" || info.Name() == configFilename() {
+		return true, nil
+	}
+
 	return project.HasBuilder(info), nil
 }
 
+// configFilename returns the name of the configuration file.
+func configFilename() string {
+	if filename := os.Getenv(envConfigFile); filename != "" {
+		return filepath.Base(filename)
+	}
+
+	return config.DefaultFilename
+}
+
 func init() {
 	plugin.Register("spotbugs", Match)
 }
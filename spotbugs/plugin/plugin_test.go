@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/testutil"
+)
+
+func TestMatch(t *testing.T) {
+	dir, cleanup := testutil.TempDir(t, "plugin")
+	defer cleanup()
+
+	testutil.WriteFiles(t, dir, map[string]string{
+		"pom.xml":                "",
+		"build.sh":               "",
+		"app.jar":                "",
+		".spotbugs-analyzer.yml": "",
+		"ci.yml":                 "",
+	})
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		file string
+		want bool
+	}{
+		{name: "Build file", file: "pom.xml", want: true},
+		{name: "Other file", file: "build.sh", want: false},
+		{name: "Archive", file: "app.jar", want: false},
+		{name: "Archive with artifacts", env: map[string]string{envArtifacts: "*.jar"}, file: "app.jar", want: true},
+		{name: "Custom build command", env: map[string]string{envBuildCommand: "./build.sh"}, file: "build.sh", want: true},
+		{name: "Configuration file", file: ".spotbugs-analyzer.yml", want: true},
+		{name: "Custom configuration file", env: map[string]string{envConfigFile: "ci.yml"}, file: "ci.yml", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{envArtifacts, envBuildCommand, envConfigFile} {
+				previous, wasDefined := os.LookupEnv(name)
+				if value, ok := tt.env[name]; ok {
+					os.Setenv(name, value)
+				} else {
+					os.Unsetenv(name)
+				}
+				defer func(name string) {
+					if wasDefined {
+						os.Setenv(name, previous)
+					} else {
+						os.Unsetenv(name)
+					}
+				}(name)
+			}
+
+			path := filepath.Join(dir, tt.file)
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Match(path, info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Match(%s) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package project
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+const (
+	// bazelTargets selects the Java targets of the workspace.
+	bazelTargets = `kind("java_library|java_binary", //...)`
+
+	// bazelJavaInfos is a Starlark expression listing the JavaInfo providers of a target. Since Bazel 7, the
+	// provider is keyed by its Starlark label, like @_builtins//:common/java/java_info.bzl%JavaInfo.
+	bazelJavaInfos = `[p for k, p in (providers(target) or {}).items() if k == "JavaInfo" or k.endswith("%JavaInfo")]`
+
+	// bazelClassJars and bazelCompileJars are Starlark expressions printing the class jars of a target and the
+	// jars of its transitive compile classpath, one per line.
+	bazelClassJars   = `"\n".join([j.class_jar.path for i in ` + bazelJavaInfos + ` for j in i.outputs.jars])`
+	bazelCompileJars = `"\n".join([j.path for i in ` + bazelJavaInfos + ` for j in i.transitive_compile_time_jars.to_list()])`
+)
+
+var errBazelNoExecRoot = errors.New("bazel didn't print the execution root of the workspace")
+
+// bazelWorkspaceFilenames are the names of the files marking the root of a Bazel workspace.
+var bazelWorkspaceFilenames = []string{"WORKSPACE", "WORKSPACE.bazel", "MODULE.bazel"}
+
+// usesBazel returns true if the builder runs Bazel.
+func (builder *builder) usesBazel() bool {
+	return builder.name == "Bazel"
+}
+
+// bazelCommand returns a Bazel command running in the project directory.
+func bazelCommand(c *cli.Context, p *Project, args ...string) *exec.Cmd {
+	return p.setupCmd(exec.Command(c.String(FlagBazelPath), args...))
+}
+
+// bazelOutput runs a Bazel command and returns the non empty lines of its standard output.
+func bazelOutput(c *cli.Context, p *Project, args ...string) ([]string, error) {
+	cmd := bazelCommand(c, p, args...)
+	output, err := cmd.Output()
+	log.Debugf("%s\n%s", cmd.String(), output)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLines(string(output)), nil
+}
+
+// parseLines returns the lines of a text, without spaces and empty lines.
+func parseLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return deleteEmpty(lines)
+}
+
+// bazelJars runs a Starlark expression on the Java targets of the workspace and returns the jars it prints,
+// resolved against the execution root of the workspace. Jars are returned once, in the order they are printed.
+func bazelJars(c *cli.Context, p *Project, expr string) ([]string, error) {
+	execRoot, err := bazelOutput(c, p, "info", "execution_root")
+	if err != nil {
+		return nil, err
+	}
+	if len(execRoot) == 0 {
+		return nil, errBazelNoExecRoot
+	}
+
+	paths, err := bazelOutput(c, p, "cquery", bazelTargets, "--output=starlark", "--starlark:expr="+expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveBazelPaths(execRoot[0], paths), nil
+}
+
+// resolveBazelPaths returns the paths relative to the execution root as absolute paths, without duplicates.
+func resolveBazelPaths(execRoot string, paths []string) []string {
+	seen := make(map[string]bool)
+	jars := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(execRoot, path)
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		jars = append(jars, path)
+	}
+
+	return jars
+}
+
+// buildBazel builds the Java targets of a Bazel workspace.
+func buildBazel(builder *builder, c *cli.Context, p *Project) error {
+	return buildGeneric(builder, c, p, func() error {
+		targets, err := bazelOutput(c, p, "query", bazelTargets, "--output=label")
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			log.Warnf("No java_library or java_binary target found in %s\n", p.Path)
+			return nil
+		}
+
+		return utils.RunCmd(bazelCommand(c, p, append([]string{"build", "--"}, targets...)...))
+	})
+}
+
+// bazelClassDirs returns the class jars of the Java targets of a Bazel workspace.
+func bazelClassDirs(builder *builder, c *cli.Context, p *Project, includeTests bool) ([]string, error) {
+	return p.bazelClassJars(c)
+}
+
+// bazelClassJars returns the class jars of the Java targets of a Bazel workspace. They are only queried once,
+// since both the class directories and the classpath of the project need them.
+func (p *Project) bazelClassJars(c *cli.Context) ([]string, error) {
+	if p.classJars != nil {
+		return p.classJars, nil
+	}
+
+	jars, err := bazelJars(c, p, bazelClassJars)
+	if err != nil {
+		return nil, err
+	}
+
+	p.classJars = jars
+	return jars, nil
+}
+
+// bazelClasspath returns the transitive compile classpath of the Java targets of a Bazel workspace, without
+// their own class jars.
+func bazelClasspath(builder *builder, c *cli.Context, p *Project, outputFile string) ([]string, error) {
+	classJars, err := p.bazelClassJars(c)
+	if err != nil {
+		return nil, err
+	}
+
+	compileJars, err := bazelJars(c, p, bazelCompileJars)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzed := make(map[string]bool, len(classJars))
+	for _, jar := range classJars {
+		analyzed[jar] = true
+	}
+
+	classpath := make([]string, 0, len(compileJars))
+	for _, jar := range compileJars {
+		if !analyzed[jar] {
+			classpath = append(classpath, jar)
+		}
+	}
+
+	return classpath, writeLines(outputFile, classpath)
+}
@@ -0,0 +1,125 @@
+package project
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli"
+)
+
+func TestParseLines(t *testing.T) {
+	got := parseLines("  bazel-out/k8-fastbuild/bin/libapp.jar\n\n\tbazel-out/k8-fastbuild/bin/libutil.jar  \n")
+	want := []string{"bazel-out/k8-fastbuild/bin/libapp.jar", "bazel-out/k8-fastbuild/bin/libutil.jar"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong lines. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
+
+func TestResolveBazelPaths(t *testing.T) {
+	paths := []string{
+		"bazel-out/k8-fastbuild/bin/libapp.jar",
+		"external/maven/v1/junit-4.12.jar",
+		"bazel-out/k8-fastbuild/bin/libapp.jar",
+		"/opt/jdk/lib/tools.jar",
+	}
+
+	got := resolveBazelPaths("/root/.cache/bazel/execroot/app", paths)
+	want := []string{
+		"/root/.cache/bazel/execroot/app/bazel-out/k8-fastbuild/bin/libapp.jar",
+		"/root/.cache/bazel/execroot/app/external/maven/v1/junit-4.12.jar",
+		"/opt/jdk/lib/tools.jar",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong jars. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
+
+func TestFindProjects_Bazel(t *testing.T) {
+	root, cleanup := newTestDir(t, map[string]string{
+		"WORKSPACE":                       "",
+		"BUILD.bazel":                     "",
+		"app/BUILD.bazel":                 "",
+		"app/src/main/java/acme/App.java": "",
+	})
+	defer cleanup()
+
+	projects, err := FindProjects(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The packages of a workspace are built from its root.
+	if len(projects) != 1 {
+		t.Fatalf("Expected 1 project but got %d", len(projects))
+	}
+	if projects[0].Path != root {
+		t.Errorf("Wrong project path. Expected %s but got %s", root, projects[0].Path)
+	}
+	if got := projects[0].builder.name; got != "Bazel" {
+		t.Errorf("Wrong builder. Expected Bazel but got %s", got)
+	}
+}
+
+func TestBazelClasspath(t *testing.T) {
+	root, err := ioutil.TempDir("", "bazel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// The fake Bazel logs its commands, and prints the jars of a target depending on a library.
+	bazel := filepath.Join(root, "bazel")
+	script := `#!/bin/sh
+echo "$1" >> "$(dirname "$0")/commands.log"
+case "$*" in
+  info*) echo /execroot ;;
+  *class_jar*) echo bazel-out/bin/libapp.jar ;;
+  *) printf 'bazel-out/bin/libapp.jar\nexternal/maven/guava.jar\n' ;;
+esac
+`
+	if err := ioutil.WriteFile(bazel, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "WORKSPACE"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	set := flag.NewFlagSet("analyze", 0)
+	set.String(FlagBazelPath, bazel, "")
+	c := cli.NewContext(nil, set, nil)
+
+	p, err := newProject(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	classpath, err := p.Classpath(c, filepath.Join(root, "classpath.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/execroot/external/maven/guava.jar"}; !reflect.DeepEqual(classpath, want) {
+		t.Errorf("Wrong classpath. Expected:\n%#v\nbut got:\n%#v", want, classpath)
+	}
+
+	classDirs, err := p.ClassDirs(c, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/execroot/bazel-out/bin/libapp.jar"}; !reflect.DeepEqual(classDirs, want) {
+		t.Errorf("Wrong class directories. Expected:\n%#v\nbut got:\n%#v", want, classDirs)
+	}
+
+	// The class jars are queried once, for the classpath.
+	commands, err := ioutil.ReadFile(filepath.Join(root, "commands.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Fields(string(commands)), []string{"info", "cquery", "info", "cquery"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong Bazel commands. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
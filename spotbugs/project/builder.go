@@ -7,7 +7,6 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
-	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/termie/go-shutil"
@@ -18,11 +17,14 @@ import (
 
 const (
 	pathExtraBuildGradle = "/spotbugs/build.gradle"
+	pathGradleInitScript = "/spotbugs/gradle/init.gradle"
 
 	// FlagAntPath is the name of spotbug's cli ant path argument
 	FlagAntPath = "antPath"
 	// FlagAntHome is the name of spotbug's cli ant home argument
 	FlagAntHome = "antHome"
+	// FlagBazelPath is the name of spotbug's cli bazel path argument
+	FlagBazelPath = "bazelPath"
 	// FlagGradlePath is the name of spotbug's cli gradle path argument
 	FlagGradlePath = "gradlePath"
 	// FlagMavenPath is the name of spotbug's cli maven path argument
@@ -36,24 +38,71 @@ const (
 )
 
 type builder struct {
-	name      string
-	filename  string
-	buildFunc func(builder *builder, context *cli.Context, p *Project) error // builds the project
+	name            string
+	filenames       []string
+	buildFunc       func(builder *builder, context *cli.Context, p *Project) error // builds the project
+	classpathFunc   classpathProcedure                                             // resolves the dependency classpath
+	cacheDirsFunc   func(context *cli.Context, p *Project) []string                // lists the dependency caches
+	classDirsFunc   classDirsProcedure                                             // lists the class directories
+	javaVersionFunc javaVersionProcedure                                           // detects the required Java version
+	classDirs       []string                                                       // glob patterns of the compiled class directories
+	testClassDirs   []string                                                       // glob patterns of the compiled test class directories
 }
 
 type procedure func() error
 
+// classDirsProcedure returns the class directories and jars of a project, when they can't be found with globs.
+type classDirsProcedure func(builder *builder, c *cli.Context, p *Project, includeTests bool) ([]string, error)
+
+// gradleProcedure builds a Gradle based project. initScript is the path of an init script to pass to Gradle,
+// or an empty string.
+type gradleProcedure func(initScript string) error
+
+// gradleClassDirs and gradleTestClassDirs are the class directories of Gradle builds, with one directory per language
+// since Gradle 4, or a single one before.
+var gradleClassDirs = []string{"build/classes/*/main", "build/classes/main"}
+var gradleTestClassDirs = []string{"build/classes/*/test", "build/classes/test"}
+
+// gradleSettingsFilenames are the names of the files marking the root of a Gradle (multi-)project build.
+var gradleSettingsFilenames = []string{"settings.gradle", "settings.gradle.kts"}
+
 // build exists only to pass a reference to the builder struct to its buildFunc function field
 func (builder *builder) build(context *cli.Context, p *Project) error {
 	return builder.buildFunc(builder, context, p)
 }
 
 func (builder *builder) canBuild(info os.FileInfo) bool {
-	return info.Name() == builder.filename
+	for _, filename := range builder.filenames {
+		if info.Name() == filename {
+			return true
+		}
+	}
+
+	return false
+}
+
+// usesGradle returns true if the builder runs Gradle.
+func (builder *builder) usesGradle() bool {
+	switch builder.name {
+	case "Grailsw", "Gradlew", "Gradle":
+		return true
+	default:
+		return false
+	}
+}
+
+// gradleArgs returns the arguments of a Gradle command running the given task, with the init script if any.
+func gradleArgs(task, initScript string) []string {
+	if initScript == "" {
+		return []string{task}
+	}
+
+	return []string{"--init-script", initScript, task}
 }
 
 // withGradleStaticCompilation returns a function that runs the given procedure after configuring a Gradle project
 // to be statically compiled. It then restores the original configuration.
+// It edits the Groovy DSL build file, and is only used when Gradle can't be given an init script.
 func withGradleStaticCompilation(p *Project, build procedure) procedure {
 	buildFile := filepath.Join(p.Path, "build.gradle")
 
@@ -170,12 +219,13 @@ func withCleanup(argPath string, build procedure) procedure {
 }
 
 // buildGradle tries building a gradle project with static compilation, and if it fails non-static compilation.
-func buildGradle(builder *builder, c *cli.Context, p *Project, build procedure) error {
+// Static compilation is configured by an init script, so that the build files of the project aren't modified.
+func buildGradle(builder *builder, c *cli.Context, p *Project, build gradleProcedure) error {
 	if p.isGroovy() {
 		// For Groovy projects, first try a static compilation as it allows FindSecBugs to find more vulnerabilites
 		log.Infof("Building %s project at %s with static compilation.\n", builder.name, p.Path)
 
-		err := withCleanup(p.Path, withGradleStaticCompilation(p, build))()
+		err := withCleanup(p.Path, func() error { return build(pathGradleInitScript) })()
 		if err == nil {
 			// Success, don't try a non static build
 			log.Info("Project built.")
@@ -187,7 +237,7 @@ func buildGradle(builder *builder, c *cli.Context, p *Project, build procedure)
 	}
 
 	log.Infof("Building %s project at %s.\n", builder.name, p.Path)
-	if err := withCleanup(p.Path, build)(); err != nil {
+	if err := withCleanup(p.Path, func() error { return build("") })(); err != nil {
 		log.Errorf("Project couldn't be built: %s\n", err.Error())
 		return err
 	}
@@ -221,95 +271,137 @@ func deleteEmpty(strs []string) []string {
 
 var builders = []builder{
 	// The SBT builder will use SBT to compile the project.
+	// SBT doesn't accept Gradle init scripts, and the Gradle static compilation only applies to build.gradle files,
+	// so SBT projects are built like the other non Gradle projects.
 	{
-		name:     "SBT",
-		filename: "build.sbt",
+		name:      "SBT",
+		filenames: []string{"build.sbt"},
 		buildFunc: func(builder *builder, c *cli.Context, p *Project) error {
-			return buildGradle(builder, c, p, func() error {
-				cmd := utils.SetupCmdNoStd(p.Path, exec.Command(c.String(FlagSBTPath), "compile"))
+			return buildGeneric(builder, c, p, func() error {
+				cmd := p.setupCmd(exec.Command(c.String(FlagSBTPath), "compile"))
 				return utils.RunCmd(cmd)
 			})
 		},
+		classpathFunc:   sbtClasspath,
+		cacheDirsFunc:   sbtCacheDirs,
+		javaVersionFunc: sbtJavaVersion,
+		classDirs:       []string{"target/scala-*/classes"},
+		testClassDirs:   []string{"target/scala-*/test-classes"},
 	},
 	// The Grailsw builder will try to run the grailsw wrapper script to compile the project.
+	// The grails command doesn't accept init scripts, so static compilation is configured in the build file,
+	// which always uses the Groovy DSL for Grails projects.
 	{
-		name:     "Grailsw",
-		filename: "grailsw",
+		name:      "Grailsw",
+		filenames: []string{"grailsw"},
 		buildFunc: func(builder *builder, c *cli.Context, p *Project) error {
-			return buildGradle(builder, c, p, func() error {
-				cmd := utils.SetupCmdNoStd(p.Path, exec.Command(path.Join(p.Path, "grailsw"), "compile"))
-				return utils.RunCmdWithTextErrorDetection(
-					cmd,
-					c,
-					"BUILD FAILED",
-					"grails failed to compile the project")
+			return buildGradle(builder, c, p, func(initScript string) error {
+				compile := func() error {
+					cmd := p.setupCmd(exec.Command(path.Join(p.Path, "grailsw"), "compile"))
+					return utils.RunCmdWithTextErrorDetection(
+						cmd,
+						c,
+						"BUILD FAILED",
+						"grails failed to compile the project")
+				}
+
+				if initScript != "" {
+					return withGradleStaticCompilation(p, compile)()
+				}
+
+				return compile()
 			})
 		},
+		classpathFunc:   gradleClasspath,
+		cacheDirsFunc:   gradleCacheDirs,
+		javaVersionFunc: gradleJavaVersion,
+		classDirs:       append([]string{"target/classes"}, gradleClassDirs...),
+		testClassDirs:   append([]string{"target/test-classes"}, gradleTestClassDirs...),
 	},
 	// The Gradlew builder will try to run the gradlew wrapper script to build the project.
 	{
-		name:     "Gradlew",
-		filename: "gradlew",
+		name:      "Gradlew",
+		filenames: []string{"gradlew"},
 		buildFunc: func(builder *builder, c *cli.Context, p *Project) error {
-			return buildGradle(builder, c, p, func() error {
-				cmd := utils.SetupCmdNoStd(p.Path, exec.Command(path.Join(p.Path, "gradlew"), "build"))
+			return buildGradle(builder, c, p, func(initScript string) error {
+				cmd := p.setupCmd(exec.Command(path.Join(p.Path, "gradlew"), gradleArgs("build", initScript)...))
 				return utils.RunCmd(cmd)
 			})
 		},
+		classpathFunc:   gradleClasspath,
+		cacheDirsFunc:   gradleCacheDirs,
+		javaVersionFunc: gradleJavaVersion,
+		classDirs:       gradleClassDirs,
+		testClassDirs:   gradleTestClassDirs,
 	},
-	// The Gradle builder will try to use Gradle to build the project.
+	// The Gradle builder will try to use Gradle to build the project, using either the Groovy or the Kotlin DSL.
+	// A directory with only a settings file is the root of a multi-project build.
 	{
-		name:     "Gradle",
-		filename: "build.gradle",
+		name:      "Gradle",
+		filenames: append([]string{"build.gradle", "build.gradle.kts"}, gradleSettingsFilenames...),
 		buildFunc: func(builder *builder, c *cli.Context, p *Project) error {
-			return buildGradle(builder, c, p, func() error {
-				cmd := utils.SetupCmdNoStd(p.Path, exec.Command(c.String(FlagGradlePath), "build"))
+			return buildGradle(builder, c, p, func(initScript string) error {
+				cmd := p.setupCmd(exec.Command(c.String(FlagGradlePath), gradleArgs("build", initScript)...))
 				return utils.RunCmd(cmd)
 			})
 		},
+		classpathFunc:   gradleClasspath,
+		cacheDirsFunc:   gradleCacheDirs,
+		javaVersionFunc: gradleJavaVersion,
+		classDirs:       gradleClassDirs,
+		testClassDirs:   gradleTestClassDirs,
+	},
+	// The Bazel builder will try to use Bazel to build the Java targets of a workspace.
+	// Every package of the workspace is built from its root.
+	{
+		name:          "Bazel",
+		filenames:     append([]string{"BUILD.bazel"}, bazelWorkspaceFilenames...),
+		buildFunc:     buildBazel,
+		classpathFunc: bazelClasspath,
+		classDirsFunc: bazelClassDirs,
 	},
 	// The Mvnw builder will try to run the mvnw wrapper script to compile the project
 	// It is lower on the list since setting up static compilation of Groovy files isn't
 	// implemented for it.
 	{
-		name:     "Mvnw",
-		filename: "mvnw",
+		name:      "Mvnw",
+		filenames: []string{"mvnw"},
 		buildFunc: func(builder *builder, c *cli.Context, p *Project) error {
 			return buildGeneric(builder, c, p, func() error {
-				args := []string{"-Dmaven.repo.local=" + c.String(FlagMavenRepoPath)}
-				args = append(args, strings.Split(c.String(FlagMavenCliOpts), " ")...)
-				args = deleteEmpty(append(args, "install"))
-				cmd := utils.SetupCmdNoStd(p.Path, exec.Command(
-					path.Join(p.Path, "mvnw"),
-					args...))
+				cmd := p.setupCmd(exec.Command(mavenExecutable(c, p), mavenArgs(c, "install")...))
 				return utils.RunCmd(cmd)
 			})
 		},
+		classpathFunc:   mavenClasspath,
+		cacheDirsFunc:   mavenCacheDirs,
+		javaVersionFunc: mavenJavaVersion,
+		classDirs:       []string{"target/classes"},
+		testClassDirs:   []string{"target/test-classes"},
 	},
 	// The Maven builder will try to use Maven to compile the project
 	// It is lower on the list since setting up static compilation of Groovy files isn't
 	// implemented for it.
 	{
-		name:     "Maven",
-		filename: "pom.xml",
+		name:      "Maven",
+		filenames: []string{"pom.xml"},
 		buildFunc: func(builder *builder, c *cli.Context, p *Project) error {
 			return buildGeneric(builder, c, p, func() error {
-				args := []string{"-Dmaven.repo.local=" + c.String(FlagMavenRepoPath)}
-				args = append(args, strings.Split(c.String(FlagMavenCliOpts), " ")...)
-				args = deleteEmpty(append(args, "install"))
-				cmd := utils.SetupCmdNoStd(p.Path, exec.Command(
-					c.String(FlagMavenPath),
-					args...))
+				cmd := p.setupCmd(exec.Command(mavenExecutable(c, p), mavenArgs(c, "install")...))
 				return utils.RunCmd(cmd)
 			})
 		},
+		classpathFunc:   mavenClasspath,
+		cacheDirsFunc:   mavenCacheDirs,
+		javaVersionFunc: mavenJavaVersion,
+		classDirs:       []string{"target/classes"},
+		testClassDirs:   []string{"target/test-classes"},
 	},
 	// The Ant builder will try to use Ant to compile the project
 	// It is lower on the list since setting up static compilation of Groovy files isn't
 	// implemented for it.
 	{
-		name:     "Ant",
-		filename: "build.xml",
+		name:      "Ant",
+		filenames: []string{"build.xml"},
 		buildFunc: func(builder *builder, c *cli.Context, p *Project) error {
 			return buildGeneric(builder, c, p, func() error {
 				if antHome := c.String(FlagAntHome); antHome != "" {
@@ -324,10 +416,14 @@ var builders = []builder{
 						defer os.Unsetenv("ANT_HOME")
 					}
 				}
-				cmd := utils.SetupCmdNoStd(p.Path, exec.Command(c.String(FlagAntPath)))
+				cmd := p.setupCmd(exec.Command(c.String(FlagAntPath)))
 				return utils.RunCmd(cmd)
 			})
 		},
+		classpathFunc: antClasspath,
+		cacheDirsFunc: antCacheDirs,
+		classDirs:     []string{"build/classes", "bin", "classes"},
+		testClassDirs: []string{"build/test/classes", "build/test-classes"},
 	},
 }
 
@@ -51,3 +51,14 @@ func Test_deleteEmpty(t *testing.T) {
 		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
 	}
 }
+
+func Test_gradleArgs(t *testing.T) {
+	if got, want := gradleArgs("build", ""), []string{"build"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+
+	want := []string{"--init-script", "/spotbugs/gradle/init.gradle", "build"}
+	if got := gradleArgs("build", pathGradleInitScript); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
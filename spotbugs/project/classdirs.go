@@ -0,0 +1,93 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// ClassDirs returns the directories containing the class files compiled by the build tool of the project and
+// its sub-projects, in lexical order. Test classes are only included when asked. Nested directories are
+// ignored, so that the same class files aren't analyzed twice. Projects built by a custom command use its class
+// directories instead.
+func (p *Project) ClassDirs(c *cli.Context, includeTests bool) ([]string, error) {
+	if len(p.classDirs) > 0 {
+		// Class directories of custom build commands.
+		return p.customClassDirs()
+	}
+
+	if p.builder == nil {
+		return nil, nil
+	}
+
+	if p.builder.classDirsFunc != nil {
+		return p.builder.classDirsFunc(p.builder, c, p, includeTests)
+	}
+
+	patterns := p.builder.classDirs
+	if includeTests {
+		patterns = append(append([]string{}, patterns...), p.builder.testClassDirs...)
+	}
+
+	var dirs []string
+	selected := make(map[string]bool)
+	err := filepath.Walk(p.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != p.Path && (strings.HasPrefix(info.Name(), ".") || p.isExcluded(path)) {
+			// Hidden directories don't contain build outputs, and Maven modules are analyzed separately.
+			return filepath.SkipDir
+		}
+		if selected[path] {
+			return filepath.SkipDir
+		}
+
+		for _, pattern := range patterns {
+			matches, err := filepath.Glob(filepath.Join(path, filepath.FromSlash(pattern)))
+			if err != nil {
+				return err
+			}
+
+			for _, match := range matches {
+				if fi, err := os.Stat(match); err != nil || !fi.IsDir() || isNested(match, dirs) {
+					continue
+				}
+
+				dirs = append(dirs, match)
+				selected[match] = true
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// isNested returns true if the path is one of the directories, one of their ancestors or one of their descendants.
+func isNested(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if isSameOrDescendant(path, dir) || isSameOrDescendant(dir, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSameOrDescendant returns true if the path is the directory or one of its descendants.
+func isSameOrDescendant(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
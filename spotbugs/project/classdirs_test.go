@@ -0,0 +1,177 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/testutil"
+)
+
+// newTestDir creates a temporary directory with the given files and their content.
+// Paths ending with a slash are created as directories.
+func newTestDir(t *testing.T, files map[string]string) (string, func()) {
+	dir, err := ioutil.TempDir("", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.WriteFiles(t, dir, files)
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// newTestProject creates a project in a temporary directory, with the given empty files and directories.
+// Paths ending with a slash are created as directories.
+func newTestProject(t *testing.T, paths ...string) (*Project, func()) {
+	files := make(map[string]string, len(paths))
+	for _, path := range paths {
+		files[path] = ""
+	}
+
+	dir, cleanup := newTestDir(t, files)
+
+	p, err := newProject(dir)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return p, cleanup
+}
+
+func TestProject_ClassDirs(t *testing.T) {
+	tests := []struct {
+		name         string
+		paths        []string
+		includeTests bool
+		want         []string
+	}{
+		{
+			name: "Gradle multi-project",
+			paths: []string{
+				"settings.gradle.kts",
+				"build/classes/java/main/",
+				"build/classes/kotlin/main/",
+				"build/classes/java/test/",
+				"lib/build.gradle.kts",
+				"lib/build/classes/java/main/",
+				".gradle/build/classes/java/main/",
+			},
+			want: []string{
+				"build/classes/java/main",
+				"build/classes/kotlin/main",
+				"lib/build/classes/java/main",
+			},
+		},
+		{
+			name: "Gradle with tests",
+			paths: []string{
+				"build.gradle",
+				"build/classes/java/main/",
+				"build/classes/java/test/",
+			},
+			includeTests: true,
+			want: []string{
+				"build/classes/java/main",
+				"build/classes/java/test",
+			},
+		},
+		{
+			name: "Maven",
+			paths: []string{
+				"pom.xml",
+				"target/classes/",
+				"target/test-classes/",
+				"target/generated-sources/",
+			},
+			want: []string{"target/classes"},
+		},
+		{
+			name: "SBT",
+			paths: []string{
+				"build.sbt",
+				"target/scala-2.13/classes/",
+				"target/scala-2.13/test-classes/",
+				"project/target/scala-2.12/sbt-1.0/classes/",
+			},
+			want: []string{"target/scala-2.13/classes"},
+		},
+		{
+			name: "Ant nested outputs",
+			paths: []string{
+				"build.xml",
+				"build/classes/",
+				"bin/",
+				"build/test-classes/",
+			},
+			want: []string{
+				"bin",
+				"build/classes",
+			},
+		},
+		{
+			name: "Ant with test classes",
+			paths: []string{
+				"build.xml",
+				"bin/",
+				"build/test-classes/",
+			},
+			want: []string{"bin"},
+		},
+		{
+			name: "Ant with tests",
+			paths: []string{
+				"build.xml",
+				"build/classes/",
+				"build/test/classes/",
+			},
+			includeTests: true,
+			want: []string{
+				"build/classes",
+				"build/test/classes",
+			},
+		},
+		{
+			name:  "Not built",
+			paths: []string{"pom.xml"},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, cleanup := newTestProject(t, tt.paths...)
+			defer cleanup()
+
+			got, err := p.ClassDirs(nil, tt.includeTests)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []string
+			for _, dir := range tt.want {
+				want = append(want, filepath.Join(p.Path, filepath.FromSlash(dir)))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+			}
+		})
+	}
+}
+
+func TestIsNested(t *testing.T) {
+	dirs := []string{"/app/build/classes", "/app/bin"}
+	for path, want := range map[string]bool{
+		"/app/build":              true,
+		"/app/build/classes":      true,
+		"/app/build/classes/main": true,
+		"/app/build/classes2":     false,
+		"/app/classes":            false,
+	} {
+		if got := isNested(path, dirs); got != want {
+			t.Errorf("isNested(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
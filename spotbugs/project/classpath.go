@@ -0,0 +1,213 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+const (
+	pathGradleClasspathScript = "/spotbugs/gradle/classpath.gradle"
+
+	gradleClasspathTask     = "spotbugsClasspath"
+	gradleClasspathProperty = "spotbugsClasspathFile"
+)
+
+// classpathProcedure resolves the dependency classpath of a project, writing it to the output file.
+type classpathProcedure func(builder *builder, c *cli.Context, p *Project, outputFile string) ([]string, error)
+
+// Classpath resolves the dependency classpath of the project with its build tool, and returns its entries.
+// The build tool writes the classpath to the given output file. It returns nil if the builder of the project
+// can't resolve classpaths.
+func (p *Project) Classpath(c *cli.Context, outputFile string) ([]string, error) {
+	if p.builder == nil || p.builder.classpathFunc == nil {
+		return nil, nil
+	}
+
+	return p.builder.classpathFunc(p.builder, c, p, outputFile)
+}
+
+// CachedJars returns the jars found in the dependency caches of the build tool of the project.
+// They are used when the classpath of the project can't be resolved.
+func (p *Project) CachedJars(c *cli.Context) ([]string, error) {
+	if p.builder == nil || p.builder.cacheDirsFunc == nil {
+		return nil, nil
+	}
+
+	var jars []string
+	for _, dir := range p.builder.cacheDirsFunc(c, p) {
+		found, err := findJars(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		jars = append(jars, found...)
+	}
+
+	return jars, nil
+}
+
+// findJars returns the .jar files of a directory tree, or nil if the directory doesn't exist.
+func findJars(root string) ([]string, error) {
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var jars []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(info.Name()) == ".jar" {
+			jars = append(jars, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jars, nil
+}
+
+// parseClasspath returns the entries of a classpath, separated by path list separators or new lines,
+// without empty entries.
+func parseClasspath(classpath string) []string {
+	entries := strings.FieldsFunc(classpath, func(r rune) bool {
+		return r == os.PathListSeparator || r == '\n' || r == '\r'
+	})
+	for i, entry := range entries {
+		entries[i] = strings.TrimSpace(entry)
+	}
+
+	return deleteEmpty(entries)
+}
+
+// readClasspath reads a classpath file written by a build tool.
+func readClasspath(outputFile string) ([]string, error) {
+	content, err := ioutil.ReadFile(outputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClasspath(string(content)), nil
+}
+
+// gradleClasspath resolves the compile classpath of every project of a Gradle build with an init script.
+// Grails projects are built with Gradle too, but their wrapper doesn't accept init scripts.
+func gradleClasspath(builder *builder, c *cli.Context, p *Project, outputFile string) ([]string, error) {
+	executable := c.String(FlagGradlePath)
+	if builder.name == "Gradlew" {
+		executable = path.Join(p.Path, "gradlew")
+	}
+
+	args := []string{
+		"--init-script", pathGradleClasspathScript,
+		"-P" + gradleClasspathProperty + "=" + outputFile,
+		"--quiet",
+		gradleClasspathTask,
+	}
+	if err := utils.RunCmd(p.setupCmd(exec.Command(executable, args...))); err != nil {
+		return nil, err
+	}
+
+	return readClasspath(outputFile)
+}
+
+// gradleCacheDirs returns the Gradle dependency cache directory.
+func gradleCacheDirs(c *cli.Context, p *Project) []string {
+	if home := os.Getenv("GRADLE_USER_HOME"); home != "" {
+		return []string{filepath.Join(home, "caches")}
+	}
+
+	return []string{filepath.Join(userHome(), ".gradle", "caches")}
+}
+
+// sbtClasspath resolves the dependency classpath of a SBT project with the export command, which prints it
+// on the last line of its output.
+func sbtClasspath(builder *builder, c *cli.Context, p *Project, outputFile string) ([]string, error) {
+	cmd := p.setupCmd(exec.Command(
+		c.String(FlagSBTPath),
+		"-Dsbt.log.noformat=true",
+		"export compile:dependencyClasspath"))
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	classpath := lastLine(string(output))
+	if err := ioutil.WriteFile(outputFile, []byte(classpath), 0644); err != nil {
+		return nil, err
+	}
+
+	return parseClasspath(classpath), nil
+}
+
+// sbtCacheDirs returns the Ivy and Coursier dependency cache directories used by SBT.
+func sbtCacheDirs(c *cli.Context, p *Project) []string {
+	return []string{
+		filepath.Join(userHome(), ".ivy2", "cache"),
+		filepath.Join(userHome(), ".cache", "coursier"),
+	}
+}
+
+// antClasspath returns the jars of the lib directories of an Ant project, since Ant doesn't resolve
+// dependencies.
+func antClasspath(builder *builder, c *cli.Context, p *Project, outputFile string) ([]string, error) {
+	var jars []string
+	err := filepath.Walk(p.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == "lib" {
+			found, err := findJars(path)
+			if err != nil {
+				return err
+			}
+
+			jars = append(jars, found...)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jars, writeLines(outputFile, jars)
+}
+
+// antCacheDirs returns the Ivy dependency cache used by Ant, and the Gradle dependency cache since Ant projects
+// are often migrated to or built along with Gradle.
+func antCacheDirs(c *cli.Context, p *Project) []string {
+	return append([]string{filepath.Join(userHome(), ".ivy2", "cache")}, gradleCacheDirs(c, p)...)
+}
+
+// writeLines writes the lines into a file.
+func writeLines(filename string, lines []string) error {
+	return ioutil.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// lastLine returns the last non empty line of a text.
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// userHome returns the home directory of the user, or the root directory if it isn't defined.
+func userHome() string {
+	home, ok := os.LookupEnv("HOME")
+	if !ok {
+		return "/"
+	}
+
+	return home
+}
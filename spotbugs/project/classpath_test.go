@@ -0,0 +1,164 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/testutil"
+)
+
+func TestParseClasspath(t *testing.T) {
+	classpath := "/m2/junit/junit/4.12/junit-4.12.jar" + string(os.PathListSeparator) +
+		"/m2/org/hamcrest/hamcrest-core/1.3/hamcrest-core-1.3.jar\n/gradle/caches/commons-io.jar\n"
+
+	want := []string{
+		"/m2/junit/junit/4.12/junit-4.12.jar",
+		"/m2/org/hamcrest/hamcrest-core/1.3/hamcrest-core-1.3.jar",
+		"/gradle/caches/commons-io.jar",
+	}
+	if got := parseClasspath(classpath); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+
+	if got := parseClasspath(""); len(got) != 0 {
+		t.Errorf("Wrong result. Expected no entry but got:\n%#v", got)
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	output := "[info] welcome to sbt\n[info] loading project\n/ivy/a.jar:/ivy/b.jar\n\n"
+	if got, want := lastLine(output), "/ivy/a.jar:/ivy/b.jar"; got != want {
+		t.Errorf("Wrong result. Expected %q but got %q", want, got)
+	}
+}
+
+// createJars creates empty files at the given paths, relative to the root directory.
+func createJars(t *testing.T, root string, paths ...string) {
+	files := make(map[string]string, len(paths))
+	for _, path := range paths {
+		files[path] = ""
+	}
+
+	testutil.WriteFiles(t, root, files)
+}
+
+func TestAntClasspath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ant-classpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	createJars(t, dir, "lib/a.jar", "lib/ext/b.jar", "web/WEB-INF/lib/c.jar", "build/d.jar", "lib/README")
+
+	outputFile := filepath.Join(dir, "classpath.txt")
+	got, err := antClasspath(nil, nil, &Project{Path: dir}, outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "lib", "a.jar"),
+		filepath.Join(dir, "lib", "ext", "b.jar"),
+		filepath.Join(dir, "web", "WEB-INF", "lib", "c.jar"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+
+	written, err := readClasspath(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(written, want) {
+		t.Errorf("Wrong classpath file. Expected:\n%#v\nbut got:\n%#v", want, written)
+	}
+}
+
+func TestProject_CachedJars(t *testing.T) {
+	home, err := ioutil.TempDir("", "gradle-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	createJars(t, home, "caches/modules-2/files-2.1/junit/junit.jar", "wrapper/dists/gradle.jar")
+
+	previous, wasDefined := os.LookupEnv("GRADLE_USER_HOME")
+	os.Setenv("GRADLE_USER_HOME", home)
+	defer func() {
+		if wasDefined {
+			os.Setenv("GRADLE_USER_HOME", previous)
+		} else {
+			os.Unsetenv("GRADLE_USER_HOME")
+		}
+	}()
+
+	p, err := newProject(filepath.Join("..", "test", "fixtures", "gradle-project"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.CachedJars(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join(home, "caches", "modules-2", "files-2.1", "junit", "junit.jar")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+
+	// Missing caches are ignored.
+	os.Setenv("GRADLE_USER_HOME", filepath.Join(home, "missing"))
+	if got, err := p.CachedJars(nil); err != nil || len(got) != 0 {
+		t.Errorf("Wrong result. Expected no jar but got:\n%#v, %v", got, err)
+	}
+}
+
+func TestProject_CachedJarsAnt(t *testing.T) {
+	home, err := ioutil.TempDir("", "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	createJars(t, home, ".ivy2/cache/junit/jars/junit.jar", ".gradle/caches/modules-2/files-2.1/guava/guava.jar")
+
+	for name, value := range map[string]string{"HOME": home, "GRADLE_USER_HOME": ""} {
+		previous, wasDefined := os.LookupEnv(name)
+		if value == "" {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, value)
+		}
+		defer func(name string) {
+			if wasDefined {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		}(name)
+	}
+
+	p, err := newProject(filepath.Join("..", "test", "fixtures", "ant-project"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.CachedJars(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(home, ".ivy2", "cache", "junit", "jars", "junit.jar"),
+		filepath.Join(home, ".gradle", "caches", "modules-2", "files-2.1", "guava", "guava.jar"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
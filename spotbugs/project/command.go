@@ -0,0 +1,113 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+const (
+	// FlagBuildCommand is the name of spotbug's cli custom build command argument
+	FlagBuildCommand = "buildCommand"
+	// FlagBuildDir is the name of spotbug's cli custom build directory argument
+	FlagBuildDir = "buildDir"
+	// FlagClassDirs is the name of spotbug's cli custom class directories argument
+	FlagClassDirs = "classDirs"
+	// FlagClasspathFile is the name of spotbug's cli custom classpath file argument
+	FlagClasspathFile = "classpathFile"
+)
+
+// commandBuilder builds the projects with a custom command, given on the command line or by the override of
+// their directory, when none of the builders can build them.
+var commandBuilder = builder{
+	name:          "Command",
+	buildFunc:     buildWithCommand,
+	classpathFunc: commandClasspath,
+}
+
+// buildWithCommand builds the project with its custom command.
+func buildWithCommand(builder *builder, c *cli.Context, p *Project) error {
+	return buildGeneric(builder, c, p, func() error {
+		cmd := p.setupCmd(exec.Command(p.buildCommand[0], p.buildCommand[1:]...))
+		return utils.RunCmd(cmd)
+	})
+}
+
+// commandClasspath reads the classpath file written by the custom command, if any.
+func commandClasspath(builder *builder, c *cli.Context, p *Project, outputFile string) ([]string, error) {
+	if p.classpathFile == "" {
+		return nil, nil
+	}
+
+	classpath, err := readClasspath(p.resolve(p.classpathFile))
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative entries are relative to the build directory.
+	for i, entry := range classpath {
+		classpath[i] = p.resolve(entry)
+	}
+
+	return classpath, nil
+}
+
+// NewCommandProject returns the project built by the custom build command given on the command line, in the
+// build directory relative to root. The command is run by the shell, so it can be a script with arguments.
+// Class directories are globs and the classpath file a path, relative to the build directory. Source files
+// excluded by the path filter, relative to root, aren't analyzed.
+func NewCommandProject(c *cli.Context, root string, filter *PathFilter) (*Project, error) {
+	p := newEmptyProject(filepath.Join(root, c.String(FlagBuildDir)), false)
+	p.root = root
+	p.pathFilter = filter
+	p.builder = &commandBuilder
+	p.buildCommand = []string{"/bin/sh", "-c", c.String(FlagBuildCommand)}
+	p.classDirs = utils.SplitList(c.String(FlagClassDirs))
+	p.classpathFile = c.String(FlagClasspathFile)
+
+	if info, err := os.Stat(p.Path); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("build directory %s doesn't exist", p.Path)
+	}
+
+	if err := p.recordSourceFiles(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// customClassDirs returns the directories and jars matching the class directory globs of the project,
+// relative to its path.
+func (p *Project) customClassDirs() ([]string, error) {
+	var dirs []string
+	for _, pattern := range p.classDirs {
+		matches, err := filepath.Glob(p.resolve(filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && (info.IsDir() || filepath.Ext(match) == ".jar") {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// resolve returns the path, relative to the project unless it is absolute.
+func (p *Project) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(p.Path, path)
+}
@@ -0,0 +1,77 @@
+package project
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli"
+)
+
+func TestNewCommandProject(t *testing.T) {
+	root, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, "service")
+	if err := os.MkdirAll(filepath.Join(dir, "src", "acme"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "src", "acme", "App.java"), []byte("package acme;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	set := flag.NewFlagSet("analyze", 0)
+	set.String(FlagBuildCommand, "mkdir -p out/classes && printf 'lib/a.jar:/opt/b.jar' > out/classpath && touch out/app.jar", "")
+	set.String(FlagBuildDir, "service", "")
+	set.String(FlagClassDirs, "out/classes, out/*.jar, out/missing", "")
+	set.String(FlagClasspathFile, "out/classpath", "")
+	c := cli.NewContext(nil, set, nil)
+
+	p, err := NewCommandProject(c, root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := p.Packages(), []string{"acme"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong packages. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+
+	if err := p.Build(c); err != nil {
+		t.Fatal(err)
+	}
+
+	classDirs, err := p.ClassDirs(c, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantClassDirs := []string{filepath.Join(dir, "out", "app.jar"), filepath.Join(dir, "out", "classes")}
+	if !reflect.DeepEqual(classDirs, wantClassDirs) {
+		t.Errorf("Wrong class directories. Expected:\n%#v\nbut got:\n%#v", wantClassDirs, classDirs)
+	}
+
+	classpath, err := p.Classpath(c, filepath.Join(root, "classpath.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantClasspath := []string{filepath.Join(dir, "lib", "a.jar"), "/opt/b.jar"}
+	if !reflect.DeepEqual(classpath, wantClasspath) {
+		t.Errorf("Wrong classpath. Expected:\n%#v\nbut got:\n%#v", wantClasspath, classpath)
+	}
+}
+
+func TestNewCommandProject_MissingDir(t *testing.T) {
+	set := flag.NewFlagSet("analyze", 0)
+	set.String(FlagBuildCommand, "true", "")
+	set.String(FlagBuildDir, "missing", "")
+	c := cli.NewContext(nil, set, nil)
+
+	if _, err := NewCommandProject(c, os.TempDir(), nil); err == nil {
+		t.Error("Expected an error for a missing build directory")
+	}
+}
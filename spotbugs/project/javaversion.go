@@ -0,0 +1,220 @@
+package project
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	filenameSdkmanrc    = ".sdkmanrc"
+	filenameJavaVersion = ".java-version"
+)
+
+// javaVersionProcedure returns the major Java version required by the build files of a directory and the name of
+// the file defining it, or empty strings if they don't define any.
+type javaVersionProcedure func(dir string) (version, filename string, err error)
+
+var (
+	// numberedVersion matches a Java version like 11, 1.8, 17.0.2 or VERSION_1_8.
+	numberedVersion = regexp.MustCompile(`[0-9]+(?:[._][0-9]+)*`)
+
+	// mavenPropertyRef matches a reference to a Maven property.
+	mavenPropertyRef = regexp.MustCompile(`^\$\{([^}]+)\}$`)
+
+	// gradleJavaVersions match the toolchain and compatibility settings of Gradle build files.
+	gradleJavaVersions = []*regexp.Regexp{
+		regexp.MustCompile(`JavaLanguageVersion\.of\(\s*["']?([0-9]+)`),
+		regexp.MustCompile(`jvmToolchain\(\s*([0-9]+)`),
+		regexp.MustCompile(`(?:source|target)Compatibility\s*=\s*(?:JavaVersion\.(?:toVersion\()?)?["']?((?:VERSION_)?[0-9][0-9._]*)`),
+	}
+
+	// sbtJavaVersions match the javac and scalac options of SBT build files.
+	sbtJavaVersions = []*regexp.Regexp{
+		regexp.MustCompile(`"--?(?:release|source|target|java-output-version)"\s*,\s*"([0-9][0-9.]*)"`),
+		regexp.MustCompile(`"-target:(?:jvm-)?([0-9][0-9.]*)"`),
+	}
+)
+
+// DetectJavaVersion returns the Java version required by the project and the name of the file defining it, or
+// empty strings if none is found. The SDKMAN identifier of a .sdkmanrc file is returned as is, other versions
+// are returned as major versions.
+func (p *Project) DetectJavaVersion() (version, filename string, err error) {
+	version, err = sdkmanrcJavaVersion(p.Path)
+	if err != nil || version != "" {
+		return version, filenameSdkmanrc, err
+	}
+
+	version, err = javaVersionFile(p.Path)
+	if err != nil || version != "" {
+		return version, filenameJavaVersion, err
+	}
+
+	if p.builder == nil || p.builder.javaVersionFunc == nil {
+		return "", "", nil
+	}
+
+	return p.builder.javaVersionFunc(p.Path)
+}
+
+// sdkmanrcJavaVersion returns the Java candidate of the .sdkmanrc file of a directory.
+func sdkmanrcJavaVersion(dir string) (string, error) {
+	f, err := os.Open(filepath.Join(dir, filenameSdkmanrc))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "java=") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "java=")), nil
+		}
+	}
+
+	return "", scanner.Err()
+}
+
+// javaVersionFile returns the major version of the .java-version file of a directory.
+func javaVersionFile(dir string) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, filenameJavaVersion))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return majorJavaVersion(string(content)), nil
+}
+
+// mavenJavaVersion returns the highest Java version set for the compiler plugin of the POM file of a directory,
+// in its configuration or with the maven.compiler and java.version properties.
+func mavenJavaVersion(dir string) (string, string, error) {
+	f, err := os.Open(filepath.Join(dir, filenamePOM))
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	var p pom
+	if err := xml.NewDecoder(f).Decode(&p); err != nil {
+		return "", "", err
+	}
+
+	properties := make(map[string]string, len(p.Properties.Entries))
+	for _, entry := range p.Properties.Entries {
+		properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+
+	resolve := func(value string) string {
+		if m := mavenPropertyRef.FindStringSubmatch(strings.TrimSpace(value)); m != nil {
+			return properties[m[1]]
+		}
+		return value
+	}
+
+	var versions []string
+	for _, plugin := range p.Plugins {
+		if plugin.ArtifactID == "maven-compiler-plugin" {
+			config := plugin.Configuration
+			versions = append(versions, resolve(config.Release), resolve(config.Source), resolve(config.Target))
+		}
+	}
+	for _, name := range []string{"maven.compiler.release", "maven.compiler.source", "maven.compiler.target"} {
+		versions = append(versions, resolve(properties[name]))
+	}
+
+	version := highestJavaVersion(versions)
+	if version == "" {
+		// Spring Boot derives the compiler settings from the java.version property.
+		version = majorJavaVersion(resolve(properties["java.version"]))
+	}
+	if version == "" {
+		return "", "", nil
+	}
+
+	return version, filenamePOM, nil
+}
+
+// gradleJavaVersion returns the highest Java version set by the toolchain or the compatibility settings of the
+// Gradle build file of a directory.
+func gradleJavaVersion(dir string) (string, string, error) {
+	return matchJavaVersion(dir, []string{"build.gradle", "build.gradle.kts"}, gradleJavaVersions)
+}
+
+// sbtJavaVersion returns the highest Java version set by the javac and scalac options of the SBT build file of a
+// directory.
+func sbtJavaVersion(dir string) (string, string, error) {
+	return matchJavaVersion(dir, []string{"build.sbt"}, sbtJavaVersions)
+}
+
+// matchJavaVersion returns the highest Java version matched by the patterns in the first existing file of a
+// directory, and the name of this file.
+func matchJavaVersion(dir string, filenames []string, patterns []*regexp.Regexp) (string, string, error) {
+	for _, filename := range filenames {
+		content, err := ioutil.ReadFile(filepath.Join(dir, filename))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+
+		var versions []string
+		for _, pattern := range patterns {
+			for _, m := range pattern.FindAllStringSubmatch(string(content), -1) {
+				versions = append(versions, m[1])
+			}
+		}
+
+		if version := highestJavaVersion(versions); version != "" {
+			return version, filename, nil
+		}
+		return "", "", nil
+	}
+
+	return "", "", nil
+}
+
+// highestJavaVersion returns the highest of the given Java versions as a major version, or an empty string if
+// none of them is a valid version.
+func highestJavaVersion(versions []string) string {
+	highest := 0
+	for _, version := range versions {
+		if major, err := strconv.Atoi(majorJavaVersion(version)); err == nil && major > highest {
+			highest = major
+		}
+	}
+
+	if highest == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(highest)
+}
+
+// majorJavaVersion returns the major version of a Java version, like 8 for 1.8 or VERSION_1_8 and 17 for 17.0.2,
+// or an empty string if it isn't a Java version.
+func majorJavaVersion(version string) string {
+	parts := strings.FieldsFunc(numberedVersion.FindString(version), func(r rune) bool {
+		return r == '.' || r == '_'
+	})
+	if len(parts) == 0 {
+		return ""
+	}
+	if parts[0] == "1" && len(parts) > 1 {
+		return parts[1]
+	}
+
+	return parts[0]
+}
@@ -0,0 +1,127 @@
+package project
+
+import (
+	"testing"
+)
+
+func TestDetectJavaVersion(t *testing.T) {
+	tests := []struct {
+		name         string
+		builder      string
+		files        map[string]string
+		wantVersion  string
+		wantFilename string
+	}{
+		{
+			name:         "Sdkmanrc",
+			builder:      "Maven",
+			files:        map[string]string{".sdkmanrc": "# Java\njava=17.0.9-tem\n", "pom.xml": "<project/>"},
+			wantVersion:  "17.0.9-tem",
+			wantFilename: ".sdkmanrc",
+		},
+		{
+			name:         "JavaVersionFile",
+			builder:      "Gradle",
+			files:        map[string]string{".java-version": "1.8\n", "build.gradle": "sourceCompatibility = 11"},
+			wantVersion:  "8",
+			wantFilename: ".java-version",
+		},
+		{
+			name:    "MavenProperties",
+			builder: "Maven",
+			files: map[string]string{"pom.xml": `<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <properties>
+    <maven.compiler.source>1.8</maven.compiler.source>
+    <maven.compiler.target>11</maven.compiler.target>
+  </properties>
+</project>`},
+			wantVersion:  "11",
+			wantFilename: "pom.xml",
+		},
+		{
+			name:    "MavenCompilerPlugin",
+			builder: "Mvnw",
+			files: map[string]string{"pom.xml": `<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <properties>
+    <jdk.release>17</jdk.release>
+  </properties>
+  <build>
+    <plugins>
+      <plugin>
+        <artifactId>maven-compiler-plugin</artifactId>
+        <configuration>
+          <release>${jdk.release}</release>
+        </configuration>
+      </plugin>
+    </plugins>
+  </build>
+</project>`},
+			wantVersion:  "17",
+			wantFilename: "pom.xml",
+		},
+		{
+			name:         "MavenSpringBoot",
+			builder:      "Maven",
+			files:        map[string]string{"pom.xml": "<project><properties><java.version>21</java.version></properties></project>"},
+			wantVersion:  "21",
+			wantFilename: "pom.xml",
+		},
+		{
+			name:         "GradleCompatibility",
+			builder:      "Gradle",
+			files:        map[string]string{"build.gradle": "sourceCompatibility = JavaVersion.VERSION_1_8\ntargetCompatibility = '1.8'\n"},
+			wantVersion:  "8",
+			wantFilename: "build.gradle",
+		},
+		{
+			name:         "GradleToolchain",
+			builder:      "Gradlew",
+			files:        map[string]string{"build.gradle.kts": "java {\n    toolchain {\n        languageVersion.set(JavaLanguageVersion.of(17))\n    }\n}\n"},
+			wantVersion:  "17",
+			wantFilename: "build.gradle.kts",
+		},
+		{
+			name:         "SBT",
+			builder:      "SBT",
+			files:        map[string]string{"build.sbt": "javacOptions ++= Seq(\"-source\", \"11\", \"-target\", \"11\")\nscalacOptions += \"-target:jvm-1.8\"\n"},
+			wantVersion:  "11",
+			wantFilename: "build.sbt",
+		},
+		{
+			name:    "None",
+			builder: "Ant",
+			files:   map[string]string{"build.xml": "<project/>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, cleanup := newTestDir(t, tt.files)
+			defer cleanup()
+
+			p := &Project{Path: dir, builder: findBuilder(tt.builder)}
+			version, filename, err := p.DetectJavaVersion()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != tt.wantVersion || filename != tt.wantFilename {
+				t.Errorf("Wrong Java version. Expected %q in %q but got %q in %q",
+					tt.wantVersion, tt.wantFilename, version, filename)
+			}
+		})
+	}
+}
+
+func TestMajorJavaVersion(t *testing.T) {
+	for version, want := range map[string]string{
+		"1.8":         "8",
+		"VERSION_1_8": "8",
+		"11":          "11",
+		"17.0.2":      "17",
+		"temurin-21":  "21",
+		"latest":      "",
+	} {
+		if got := majorJavaVersion(version); got != want {
+			t.Errorf("Wrong major version of %s. Expected %q but got %q", version, want, got)
+		}
+	}
+}
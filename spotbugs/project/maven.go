@@ -0,0 +1,118 @@
+package project
+
+import (
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
+)
+
+const filenamePOM = "pom.xml"
+
+// pom maps to the parts of a Maven POM file used by the analyzer.
+type pom struct {
+	Modules    []string `xml:"modules>module"`
+	Properties struct {
+		Entries []pomProperty `xml:",any"`
+	} `xml:"properties"`
+	Plugins []pomPlugin `xml:"build>plugins>plugin"`
+}
+
+// pomProperty is a property of a POM file, named after its element.
+type pomProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// pomPlugin is a build plugin of a POM file, with the compiler settings of its configuration.
+type pomPlugin struct {
+	ArtifactID    string `xml:"artifactId"`
+	Configuration struct {
+		Release string `xml:"release"`
+		Source  string `xml:"source"`
+		Target  string `xml:"target"`
+	} `xml:"configuration"`
+}
+
+// mavenModules returns the absolute paths of the module directories declared by the POM file of the given
+// directory, or nil if it doesn't declare any module. A module can reference a directory or a POM file.
+func mavenModules(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, filenamePOM))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var p pom
+	if err := xml.NewDecoder(f).Decode(&p); err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []string
+	for _, module := range p.Modules {
+		module = strings.TrimSpace(module)
+		if module == "" {
+			continue
+		}
+
+		modulePath := filepath.Join(absDir, filepath.FromSlash(module))
+		if strings.HasSuffix(module, ".xml") {
+			modulePath = filepath.Dir(modulePath)
+		}
+
+		modules = append(modules, modulePath)
+	}
+
+	return modules, nil
+}
+
+// mavenExecutable returns the path of the Maven executable building the project: its wrapper script if it
+// has one, or the Maven command.
+func mavenExecutable(c *cli.Context, p *Project) string {
+	if p.builder != nil && p.builder.name == "Mvnw" {
+		return path.Join(p.Path, "mvnw")
+	}
+
+	return c.String(FlagMavenPath)
+}
+
+// mavenArgs returns the arguments of a Maven command running the given goals, with the local repository
+// and the user defined options.
+func mavenArgs(c *cli.Context, goals ...string) []string {
+	args := []string{"-Dmaven.repo.local=" + c.String(FlagMavenRepoPath)}
+	args = append(args, strings.Split(c.String(FlagMavenCliOpts), " ")...)
+	return deleteEmpty(append(args, goals...))
+}
+
+// mavenClasspath resolves the dependency classpath of a Maven project with the dependency plugin.
+func mavenClasspath(builder *builder, c *cli.Context, p *Project, outputFile string) ([]string, error) {
+	args := mavenArgs(c, "dependency:build-classpath", "-Dmdep.outputFile="+outputFile)
+	cmd := p.setupCmd(exec.Command(mavenExecutable(c, p), args...))
+	if err := utils.RunCmd(cmd); err != nil {
+		return nil, err
+	}
+
+	return readClasspath(outputFile)
+}
+
+// mavenCacheDirs returns the Maven local repository.
+func mavenCacheDirs(c *cli.Context, p *Project) []string {
+	localRepo := c.String(FlagMavenRepoPath)
+	if !filepath.IsAbs(localRepo) {
+		// This path is relative to the project path, get a full path.
+		localRepo = filepath.Join(p.Path, localRepo)
+	}
+
+	return []string{localRepo}
+}
@@ -0,0 +1,54 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMavenModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maven-modules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pom := `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modules>
+    <module>core</module>
+    <module> web/app </module>
+    <module>../sibling/pom-alt.xml</module>
+  </modules>
+</project>`
+	if err := ioutil.WriteFile(filepath.Join(dir, "pom.xml"), []byte(pom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := mavenModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "core"),
+		filepath.Join(dir, "web", "app"),
+		filepath.Join(filepath.Dir(dir), "sibling"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
+
+func TestMavenModules_NoModules(t *testing.T) {
+	got, err := mavenModules(filepath.Join("..", "test", "fixtures", "maven-project"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != nil {
+		t.Errorf("Wrong result. Expected no module but got:\n%#v", got)
+	}
+}
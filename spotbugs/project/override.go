@@ -0,0 +1,70 @@
+package project
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Override changes how the project of a directory is found and built.
+type Override struct {
+	Builder      string   // name of the builder used instead of the best one
+	BuildCommand []string // command and arguments building the project instead of its builder
+	JavaVersion  string   // major Java version used to build the project
+	Skip         bool     // the directory and its descendants aren't analyzed
+}
+
+// Overrides are the overrides of directories, by path relative to the directory where projects are searched.
+// Paths use slashes, and the directory itself is ".".
+type Overrides map[string]Override
+
+// get returns the override of a directory, found while searching projects in root.
+func (o Overrides) get(root, directory string) Override {
+	rel, err := filepath.Rel(root, directory)
+	if err != nil {
+		return Override{}
+	}
+
+	return o[filepath.ToSlash(rel)]
+}
+
+// skippedDirs returns the absolute paths of the skipped directories, when searching projects in root.
+func (o Overrides) skippedDirs(root string) map[string]bool {
+	skipped := make(map[string]bool)
+	for path, override := range o {
+		if !override.Skip {
+			continue
+		}
+
+		if absPath, err := filepath.Abs(filepath.Join(root, filepath.FromSlash(path))); err == nil {
+			skipped[absPath] = true
+		}
+	}
+
+	return skipped
+}
+
+// findBuilder returns the builder with the given name, ignoring case, or nil if there is none.
+func findBuilder(name string) *builder {
+	for i := range builders {
+		if strings.EqualFold(builders[i].name, name) {
+			return &builders[i]
+		}
+	}
+
+	return nil
+}
+
+// BuilderNames returns the names of the builders that can be selected in an override.
+func BuilderNames() []string {
+	names := make([]string, len(builders))
+	for i, b := range builders {
+		names[i] = b.name
+	}
+
+	return names
+}
+
+// IsBuilderName returns true if a builder has the given name, ignoring case.
+func IsBuilderName(name string) bool {
+	return findBuilder(name) != nil
+}
@@ -0,0 +1,109 @@
+package project
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/pathfilter"
+)
+
+func TestFindProjectsWithOverrides(t *testing.T) {
+	dir, cleanup := newTestDir(t, map[string]string{
+		"pom.xml":                           "<project></project>",
+		"build.gradle":                      "",
+		"src/main/java/app/App.java":        "package app;",
+		"examples/src/Example.java":         "package examples;",
+		"examples/pom.xml":                  "<project></project>",
+		"legacy/src/legacy/Legacy.java":     "package legacy;",
+		"services/billing/build.xml":        "",
+		"services/billing/src/Bill.java":    "package billing;",
+		"services/billing/skipped/Gen.java": "package generated;",
+	})
+	defer cleanup()
+
+	overrides := Overrides{
+		".":                        {Builder: "maven", JavaVersion: "11"},
+		"examples":                 {Skip: true},
+		"legacy":                   {BuildCommand: []string{"./build.sh"}},
+		"services/billing/skipped": {Skip: true},
+	}
+
+	projects, err := FindProjectsWithOverrides(dir, true, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		path, builder, javaVersion string
+		packages                   []string
+	}
+	var got []result
+	for _, p := range projects {
+		rel, _ := filepath.Rel(dir, p.Path)
+		packages := p.Packages()
+		sort.Strings(packages)
+		got = append(got, result{filepath.ToSlash(rel), p.builder.name, p.JavaVersion(), packages})
+	}
+
+	sort.Slice(got, func(i, j int) bool { return got[i].path < got[j].path })
+
+	want := []result{
+		{".", "Maven", "11", []string{"app", "billing", "legacy"}},
+		{"legacy", "Command", "", []string{"legacy"}},
+		{"services/billing", "Ant", "", []string{"billing"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
+
+func TestFindBuilder(t *testing.T) {
+	if b := findBuilder("GRADLEW"); b == nil || b.name != "Gradlew" {
+		t.Errorf("findBuilder(GRADLEW) = %v, want the Gradlew builder", b)
+	}
+
+	if IsBuilderName("make") {
+		t.Error("IsBuilderName(make) = true, want false")
+	}
+}
+
+func TestFindProjectsWithFilter(t *testing.T) {
+	dir, cleanup := newTestDir(t, map[string]string{
+		"pom.xml":                                  "<project></project>",
+		"src/main/java/app/App.java":               "package app;",
+		"src/test/java/app/tests/AppTest.java":     "package app.tests;",
+		"third_party/lib/pom.xml":                  "<project></project>",
+		"third_party/lib/src/main/java/Lib.java":   "package lib;",
+		"tools/build.xml":                          "",
+		"tools/src/integrationTest/java/IT.java":   "package tools.it;",
+		"tools/src/main/java/tools/Generator.java": "package tools;",
+	})
+	defer cleanup()
+
+	filter := &PathFilter{
+		Excluded:  pathfilter.Filter{ExcludedPaths: []string{"third_party"}},
+		TestPaths: []string{"src/test", "src/*Test"},
+	}
+	projects, err := FindProjectsWithFilter(dir, true, nil, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string][]string)
+	for _, p := range projects {
+		rel, _ := filepath.Rel(dir, p.Path)
+		packages := p.Packages()
+		sort.Strings(packages)
+		got[filepath.ToSlash(rel)] = packages
+	}
+
+	want := map[string][]string{
+		".":     {"app", "tools"},
+		"tools": {"tools"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+}
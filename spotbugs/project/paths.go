@@ -0,0 +1,69 @@
+package project
+
+import (
+	"path/filepath"
+	"strings"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/pathfilter"
+)
+
+// PathFilter selects the analyzed files and directories by their path relative to the analyzed directory, with the
+// patterns of the common path filter. Test paths match from any directory of the path, so that src/test matches the
+// test sources of every module.
+type PathFilter struct {
+	Excluded  pathfilter.Filter // files and directories not analyzed, like SAST_EXCLUDED_PATHS
+	TestPaths []string          // test source directories not analyzed, wherever they are
+	Included  []string          // if any, only the source files matching one of them are analyzed
+}
+
+// IsExcluded returns true if the source file isn't analyzed: it is in an excluded directory, or included paths are
+// given and it matches none of them.
+func (f *PathFilter) IsExcluded(path string) bool {
+	if f == nil {
+		return false
+	}
+
+	if f.IsExcludedDir(path) {
+		return true
+	}
+
+	return len(f.Included) > 0 && !matchAny(f.Included, path)
+}
+
+// IsExcludedDir returns true if the directory and everything it contains aren't analyzed. Included paths don't
+// apply to directories, as they can contain included files.
+func (f *PathFilter) IsExcludedDir(path string) bool {
+	if f == nil {
+		return false
+	}
+
+	if f.Excluded.IsExcluded(path) {
+		return true
+	}
+
+	// Match the test paths from every directory of the path.
+	parts := strings.Split(filepath.Clean(path), string(filepath.Separator))
+	for i := range parts {
+		if matchAny(f.TestPaths, filepath.Join(parts[i:]...)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsEmpty returns true if the filter doesn't exclude any path.
+func (f *PathFilter) IsEmpty() bool {
+	return f == nil || len(f.Excluded.ExcludedPaths) == 0 && len(f.TestPaths) == 0 && len(f.Included) == 0
+}
+
+// matchAny returns true if one of the patterns matches the path. Malformed patterns match nothing.
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if matched, err := pathfilter.Match(pattern, path); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,47 @@
+package project
+
+import (
+	"testing"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/pathfilter"
+)
+
+func TestPathFilter(t *testing.T) {
+	f := &PathFilter{
+		Excluded:  pathfilter.Filter{ExcludedPaths: []string{"third_party", "*.groovy"}},
+		TestPaths: []string{"src/test", "src/*Test"},
+		Included:  []string{"services", "App.java"},
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"App.java", false},
+		{"services/billing/src/main/java/Bill.java", false},
+		{"services/billing/src/test/java/BillTest.java", true},
+		{"services/billing/src/integrationTest/java/BillIT.java", true},
+		{"services/billing/src/main/java/test/Fixture.java", false},
+		{"services/billing/src/main/groovy/Bill.groovy", true},
+		{"services/third_party/Lib.java", true},
+		{"tools/src/main/java/Tool.java", true},
+	}
+	for _, test := range tests {
+		if got := f.IsExcluded(test.path); got != test.want {
+			t.Errorf("IsExcluded(%s) = %v, want %v", test.path, got, test.want)
+		}
+	}
+
+	// Included paths don't apply to directories.
+	if f.IsExcludedDir("tools") {
+		t.Error("IsExcludedDir(tools) = true, want false")
+	}
+	if !f.IsExcludedDir("lib/src/test") {
+		t.Error("IsExcludedDir(lib/src/test) = false, want true")
+	}
+
+	var none *PathFilter
+	if none.IsExcluded("src/test/java/AppTest.java") || !none.IsEmpty() {
+		t.Error("A nil filter excludes paths")
+	}
+}
@@ -9,16 +9,20 @@
 package project
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 
 	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/directory"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/utils"
 )
 
 // Project represents a buildable project.
@@ -27,6 +31,19 @@ type Project struct {
 	SourceFilesTree *directory.Directory
 	builder         *builder
 	packages        map[string]bool
+	sourcePackages  map[string]string // package of each source file, by path relative to the project
+	modules         map[string]bool   // absolute paths of the Maven modules built with the project
+	reactor         *Project          // root of the Maven reactor building the project, if any
+	sourcesOnly     bool              // the project is only used to find source files
+	skipped         map[string]bool   // absolute paths of the directories skipped by overrides
+	buildCommand    []string          // command building the project instead of its builder, if any
+	javaVersion     string            // major Java version building the project, if any
+	javaHome        string            // home directory of the JDK running the builder, if any
+	classDirs       []string          // globs of the class directories written by the build command, if any
+	classpathFile   string            // file listing the classpath written by the build command, if any
+	root            string            // directory where the project was found, to which paths are relative
+	pathFilter      *PathFilter       // files and directories excluded from the analysis, relative to the root
+	classJars       []string          // class jars of the Bazel targets of the project, once computed
 }
 
 type errNoCompatibleBuilder struct {
@@ -40,18 +57,55 @@ func (e errNoCompatibleBuilder) Error() string {
 type filesFirstWalkFunc func(directory string, infos []os.FileInfo) error
 
 var packageMatcher = regexp.MustCompile("package\\s+([a-z][a-z0-9_\\.]*)")
-var sourceFileMatcher = regexp.MustCompile("(\\.groovy|\\.java|\\.scala)$")
+var sourceFileMatcher = regexp.MustCompile("(\\.groovy|\\.java|\\.scala|\\.kts?)$")
+var kotlinFileMatcher = regexp.MustCompile("\\.kts?$")
+
+// kotlinPackageMatcher matches Kotlin package declarations, which aren't followed by semicolons and whose identifiers
+// can be enclosed in backticks.
+var kotlinPackageMatcher = regexp.MustCompile("(?m)^\\s*package\\s+((?:`[^`\\n]+`|[\\pL_][\\pL\\pN_]*)(?:\\s*\\.\\s*(?:`[^`\\n]+`|[\\pL_][\\pL\\pN_]*))*)")
 var groovyFileMatcher = regexp.MustCompile("\\.groovy$")
 
 // FindProjects walks the directory tree and returns a list of detected Project that can be built and analyzed
 // It doesn't use filepath.Walk because it walks files one at a time which is unsuitable in our case ; we need
 // the full list of files in a directory to determine which builder is best suited for it.
 func FindProjects(path string, quiet bool) ([]Project, error) {
+	return FindProjectsWithOverrides(path, quiet, nil)
+}
+
+// FindProjectsWithOverrides walks the directory tree like FindProjects, applying the overrides of the directories.
+// Skipped directories are ignored, and a project is created for every directory whose builder or build command is
+// overridden, even if none of the builders can build it.
+func FindProjectsWithOverrides(path string, quiet bool, overrides Overrides) ([]Project, error) {
+	return FindProjectsWithFilter(path, quiet, overrides, nil)
+}
+
+// FindProjectsWithFilter walks the directory tree like FindProjectsWithOverrides, ignoring the directories and
+// source files excluded by the path filter, which matches their path relative to the walked directory.
+func FindProjectsWithFilter(path string, quiet bool, overrides Overrides, filter *PathFilter) ([]Project, error) {
 	projects := make([]Project, 0)
+	skipped := overrides.skippedDirs(path)
+
+	// Root project of the Maven reactor building each module, by absolute module path.
+	reactors := make(map[string]*Project)
 
 	err := filesFirstWalk(path, func(directory string, infos []os.FileInfo) error {
+		override := overrides.get(path, directory)
+		if override.Skip {
+			if !quiet {
+				log.Infof("Skipping %s directory\n", directory)
+			}
+			return filepath.SkipDir
+		}
+
+		if rel, err := filepath.Rel(path, directory); err == nil && rel != "." && filter.IsExcludedDir(rel) {
+			if !quiet {
+				log.Infof("Skipping %s directory, excluded by the path filter\n", directory)
+			}
+			return filepath.SkipDir
+		}
+
 		// Test buildability of each file.
-		foundBuilder := false
+		foundBuilder := override.Builder != "" || len(override.BuildCommand) > 0
 		for _, f := range infos {
 			if HasBuilder(f) {
 				foundBuilder = true
@@ -64,17 +118,42 @@ func FindProjects(path string, quiet bool) ([]Project, error) {
 		}
 
 		// Create a project for this directory.
-		project, err := newProject(directory)
+		project, err := newProjectWithOverride(directory, override, skipped, path, filter)
 		if err != nil {
 			return err
 		}
 
+		if absPath, err := filepath.Abs(directory); err == nil {
+			project.reactor = reactors[absPath]
+		}
+
+		root := project.BuiltBy()
+		for module := range project.modules {
+			reactors[module] = root
+		}
+
 		if !quiet {
-			log.Infof("Found %s project in %s directory\n", project.builder.name, directory)
+			if project.reactor != nil {
+				log.Infof("Found %s project in %s directory, built by the Maven reactor in %s directory\n",
+					project.builder.name, directory, project.reactor.Path)
+			} else {
+				log.Infof("Found %s project in %s directory\n", project.builder.name, directory)
+			}
 		}
 
 		projects = append(projects, *project)
 
+		if project.builder.usesGradle() && hasGradleSettings(infos) {
+			// Gradle builds all the sub-projects of a multi-project build from its root, so they
+			// must not be built and analyzed again.
+			return filepath.SkipDir
+		}
+
+		if project.builder.usesBazel() {
+			// Bazel builds all the packages of a workspace from its root.
+			return filepath.SkipDir
+		}
+
 		// Keep searching the descendant for possible sub-projects.
 		return nil
 	})
@@ -85,6 +164,19 @@ func FindProjects(path string, quiet bool) ([]Project, error) {
 	return projects, nil
 }
 
+// hasGradleSettings returns true if a Gradle settings file is present in the files.
+func hasGradleSettings(infos []os.FileInfo) bool {
+	for _, info := range infos {
+		for _, filename := range gradleSettingsFilenames {
+			if info.Name() == filename {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // filesFirstWalk walks a tree, and gives a list of all the file info to the provided function so that
 // the function can make decisions it couldn't with a classic filepath.Walk.
 func filesFirstWalk(root string, walkFn filesFirstWalkFunc) error {
@@ -175,16 +267,34 @@ func filesFirstWalkRec(path string, walkFn filesFirstWalkFunc) error {
 }
 
 func newProject(path string) (*Project, error) {
-	p := new(Project)
-	p.Path = path
-	p.SourceFilesTree = directory.NewDirectory("", nil)
-	p.packages = make(map[string]bool)
+	return newProjectWithOverride(path, Override{}, nil, path, nil)
+}
 
-	err := p.recordSourceFiles()
-	if err != nil {
+// newProjectWithOverride creates the project of a directory, applying its override. Skipped directories,
+// given as absolute paths, and the paths excluded by the filter, relative to the root, don't belong to the project.
+func newProjectWithOverride(path string, override Override, skipped map[string]bool, root string, filter *PathFilter) (*Project, error) {
+	p := newEmptyProject(path, false)
+	p.skipped = skipped
+	p.root = root
+	p.pathFilter = filter
+	p.buildCommand = override.BuildCommand
+	p.javaVersion = override.JavaVersion
+
+	if override.Builder != "" {
+		// Pin the builder before recording source files, as Maven modules depend on it.
+		if p.builder = findBuilder(override.Builder); p.builder == nil {
+			return nil, fmt.Errorf("unknown builder %s for project path: %s", override.Builder, path)
+		}
+	}
+
+	if err := p.recordSourceFiles(); err != nil {
 		return nil, err
 	}
 
+	if p.builder == nil && len(p.buildCommand) > 0 {
+		p.builder = &commandBuilder
+	}
+
 	if p.builder == nil {
 		return nil, errNoCompatibleBuilder{path}
 	}
@@ -192,6 +302,30 @@ func newProject(path string) (*Project, error) {
 	return p, nil
 }
 
+// NewSourceProject returns a project recording the source files of a directory, even if it can't be built.
+// It is used to find the source files of classes compiled elsewhere.
+func NewSourceProject(path string) (*Project, error) {
+	p := newEmptyProject(path, true)
+	if err := p.recordSourceFiles(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// newEmptyProject returns a project of the directory without any source file.
+func newEmptyProject(path string, sourcesOnly bool) *Project {
+	p := new(Project)
+	p.Path = path
+	p.sourcesOnly = sourcesOnly
+	p.SourceFilesTree = directory.NewDirectory("", nil)
+	p.packages = make(map[string]bool)
+	p.sourcePackages = make(map[string]string)
+	p.modules = make(map[string]bool)
+
+	return p
+}
+
 // UsesMaven convenience function, returns true if the project uses Maven or its variants.
 func (p *Project) UsesMaven() bool {
 	switch p.builder.name {
@@ -202,6 +336,89 @@ func (p *Project) UsesMaven() bool {
 	}
 }
 
+// BuiltBy returns the project whose build compiles this project: the root of its Maven reactor, or the project itself.
+func (p *Project) BuiltBy() *Project {
+	if p.reactor != nil {
+		return p.reactor
+	}
+
+	return p
+}
+
+// IsModule returns true if the directory is a module of the Maven reactor of the project. Modules are separate
+// projects, so their source and class files don't belong to the project.
+func (p *Project) IsModule(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	return p.modules[absPath]
+}
+
+// isExcluded returns true if the directory doesn't belong to the project: it is a Maven module of the project,
+// a skipped directory or a directory excluded by the path filter.
+func (p *Project) isExcluded(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	return p.modules[absPath] || p.skipped[absPath] || p.isFilteredDir(path)
+}
+
+// isFiltered returns true if the path filter excludes the source file.
+func (p *Project) isFiltered(path string) bool {
+	rel, ok := p.filteredPath(path)
+	return ok && p.pathFilter.IsExcluded(rel)
+}
+
+// isFilteredDir returns true if the path filter excludes the directory.
+func (p *Project) isFilteredDir(path string) bool {
+	rel, ok := p.filteredPath(path)
+	return ok && p.pathFilter.IsExcludedDir(rel)
+}
+
+// filteredPath returns the path relative to the root, and false if the path filter excludes nothing.
+func (p *Project) filteredPath(path string) (string, bool) {
+	if p.pathFilter.IsEmpty() {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(p.root, path)
+	if err != nil {
+		return "", false
+	}
+
+	return rel, true
+}
+
+// JavaVersion returns the major Java version building the project, or an empty string to use the default one.
+func (p *Project) JavaVersion() string {
+	return p.javaVersion
+}
+
+// JavaHome returns the home directory of the JDK running the builder of the project, or an empty string if it
+// uses the default one.
+func (p *Project) JavaHome() string {
+	return p.javaHome
+}
+
+// SetJavaHome selects the JDK running the builder of the project, instead of the default one.
+func (p *Project) SetJavaHome(javaHome string) {
+	p.javaHome = javaHome
+}
+
+// setupCmd sets up a command running in the project directory with the JDK of the project.
+func (p *Project) setupCmd(cmd *exec.Cmd) *exec.Cmd {
+	return utils.SetupCmdNoStd(p.Path, cmd, utils.JavaEnv(p.javaHome)...)
+}
+
+// HasSourceFiles returns true if the project has source files of its own.
+func (p *Project) HasSourceFiles() bool {
+	return len(p.sourcePackages) > 0
+}
+
 // Packages return a list of packages present in the project source code files, without duplicates.
 func (p *Project) Packages() []string {
 	keys := make([]string, len(p.packages))
@@ -233,31 +450,74 @@ func (p *Project) addSourceFile(path string) error {
 	p.SourceFilesTree.AddSourceFileComponents(components)
 
 	// Add package name
-	return p.addPackageFromSourceFile(path)
+	return p.addPackageFromSourceFile(path, relPath)
 }
 
 // addPackageFromSourceFile reads a java or groovy file and add the detected package name to the
 // project.
-func (p *Project) addPackageFromSourceFile(path string) error {
+func (p *Project) addPackageFromSourceFile(path, relPath string) error {
 	content, err := ioutil.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
-	match := packageMatcher.FindSubmatch(content)
-	if len(match) > 1 {
-		p.packages[string(match[1])] = true
+	// Files in the default package are recorded with an empty package name.
+	p.sourcePackages[relPath] = ""
+
+	pkg := ""
+	if kotlinFileMatcher.MatchString(path) {
+		pkg = kotlinPackage(content)
+	} else if match := packageMatcher.FindSubmatch(content); len(match) > 1 {
+		pkg = string(match[1])
+	}
+
+	if pkg != "" {
+		p.packages[pkg] = true
+		p.sourcePackages[relPath] = pkg
 	}
 
 	return nil
 }
 
-// Build builds the project.
+// kotlinPackage returns the package declared in a Kotlin source file, without backticks and spaces.
+func kotlinPackage(content []byte) string {
+	match := kotlinPackageMatcher.FindSubmatch(content)
+	if len(match) < 2 {
+		return ""
+	}
+
+	return strings.NewReplacer("`", "", " ", "", "\t", "").Replace(string(match[1]))
+}
+
+// HasSourceFile returns true if the source file, relative to the project path, belongs to the project.
+func (p *Project) HasSourceFile(relPath string) bool {
+	_, ok := p.sourcePackages[filepath.Clean(relPath)]
+	return ok
+}
+
+// RestrictToSourceFiles limits the packages of the project to the ones of the given source files,
+// relative to the project path. Files that don't belong to the project are ignored.
+func (p *Project) RestrictToSourceFiles(relPaths []string) {
+	packages := make(map[string]bool)
+	for _, relPath := range relPaths {
+		if pkg := p.sourcePackages[filepath.Clean(relPath)]; pkg != "" {
+			packages[pkg] = true
+		}
+	}
+
+	p.packages = packages
+}
+
+// Build builds the project, with the command of its override if any.
 func (p *Project) Build(c *cli.Context) error {
+	if len(p.buildCommand) > 0 {
+		return buildWithCommand(p.builder, c, p)
+	}
+
 	return p.builder.build(c, p)
 }
 
-// recordSourceFiles explores the project tree, to add every Java and Groovy source files
+// recordSourceFiles explores the project tree, to add every Java, Groovy, Scala and Kotlin source files
 // to the SourceFile field, relative to the project root.
 // This is used after running the tool to filter results.
 // It also detects which builders can build the project.
@@ -271,14 +531,23 @@ func (p *Project) recordSourceFilesWalk(directory string, infos []os.FileInfo) e
 		return filepath.SkipDir
 	}
 
+	if directory != p.Path && p.isExcluded(directory) {
+		// Maven modules are analyzed as separate projects, and skipped or filtered directories aren't analyzed.
+		return filepath.SkipDir
+	}
+
 	if p.builder == nil {
 		// Set best builder for these files in the project (or nil if none if detected).
 		p.builder = bestBuilder(infos)
 	}
 
+	if directory == p.Path && !p.sourcesOnly && p.builder != nil && p.UsesMaven() {
+		p.recordModules()
+	}
+
 	// Add source files.
 	for _, info := range infos {
-		if sourceFileMatcher.MatchString(info.Name()) {
+		if sourceFileMatcher.MatchString(info.Name()) && !p.isFiltered(filepath.Join(directory, info.Name())) {
 			// Add recognized source code files to the project
 			if err := p.addSourceFile(filepath.Join(directory, info.Name())); err != nil {
 				return err
@@ -289,9 +558,23 @@ func (p *Project) recordSourceFilesWalk(directory string, infos []os.FileInfo) e
 	return nil
 }
 
+// recordModules records the modules declared by the POM file of a Maven project.
+func (p *Project) recordModules() {
+	modules, err := mavenModules(p.Path)
+	if err != nil {
+		log.Warnf("Couldn't read the Maven modules of %s: %s\n", p.Path, err.Error())
+		return
+	}
+
+	for _, module := range modules {
+		p.modules[module] = true
+	}
+}
+
 // RelativePath takes a path reported by FindSecBug and returns the
 // path relative to the project root.
 // Exemple:
+//
 //   path: org/gizmotech/awesometool/Wow.java
 //
 //   result: awesometool/mysubfolder/src/main/java/org/gizmotech/awesometool/Wow.java
@@ -299,8 +582,40 @@ func (p *Project) RelativePath(path string) (string, error) {
 	// Get the directory containing the source file
 	f, fileName, err := p.SourceFilesTree.GetMatchingPath(path)
 	if err != nil {
+		// Kotlin source files don't have to be in a directory matching their package.
+		if relPath, ok := p.relativePathFromPackage(path); ok {
+			return relPath, nil
+		}
+
 		return "", err
 	}
 
 	return filepath.Join(f.PathRelativeTo(p.SourceFilesTree), fileName), nil
 }
+
+// relativePathFromPackage finds the source file whose declared package and file name match the reported path.
+// When several files match, the first one in lexical order is returned.
+func (p *Project) relativePathFromPackage(path string) (string, bool) {
+	var candidates []string
+	for relPath, pkg := range p.sourcePackages {
+		if !kotlinFileMatcher.MatchString(relPath) {
+			continue
+		}
+
+		components := append(strings.Split(pkg, "."), filepath.Base(relPath))
+		if pkg == "" {
+			components = components[1:]
+		}
+
+		if filepath.Join(components...) == filepath.Clean(path) {
+			candidates = append(candidates, relPath)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	sort.Strings(candidates)
+	return candidates[0], true
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -13,8 +14,61 @@ func TestFindProjects(t *testing.T) {
 		t.Errorf("%s\n", err.Error())
 	}
 
-	if len(projects) != 12 {
-		t.Errorf("%d projects found, wanted 12.", len(projects))
+	if len(projects) != 16 {
+		t.Errorf("%d projects found, wanted 16.", len(projects))
+	}
+}
+
+func TestFindProjects_GradleMultiProject(t *testing.T) {
+	projects, err := FindProjects(filepath.Join("..", "test", "fixtures", "gradle-multi-project"), true)
+	if err != nil {
+		t.Fatalf("%s\n", err.Error())
+	}
+
+	if len(projects) != 1 {
+		t.Fatalf("%d projects found, wanted a single multi-project build.", len(projects))
+	}
+
+	got := projects[0].Packages()
+	sort.Strings(got)
+	want := []string{"com.gitlab.security_products.app", "com.gitlab.security_products.lib"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Project.Packages() = %v, want %v", got, want)
+	}
+}
+
+func TestFindProjects_MavenReactor(t *testing.T) {
+	root := filepath.Join("..", "test", "fixtures", "maven-multimodule-project")
+	projects, err := FindProjects(root, true)
+	if err != nil {
+		t.Fatalf("%s\n", err.Error())
+	}
+
+	if len(projects) != 4 {
+		t.Fatalf("%d projects found, wanted the reactor and its 3 modules.", len(projects))
+	}
+
+	reactor := projects[0]
+	if reactor.Path != root {
+		t.Fatalf("First project is %s, wanted the reactor %s", reactor.Path, root)
+	}
+	if reactor.HasSourceFiles() {
+		t.Errorf("Reactor has the source files of its modules: %v", reactor.Packages())
+	}
+	if reactor.BuiltBy().Path != root {
+		t.Errorf("Reactor is built by %s, wanted itself", reactor.BuiltBy().Path)
+	}
+
+	for _, module := range projects[1:] {
+		if !reactor.IsModule(module.Path) {
+			t.Errorf("%s isn't a module of the reactor", module.Path)
+		}
+		if got := module.BuiltBy().Path; got != root {
+			t.Errorf("%s is built by %s, wanted %s", module.Path, got, root)
+		}
+		if !module.HasSourceFile(filepath.Join("src", "main", "java", "com", "gitlab", "security_products", "tests", "App.java")) {
+			t.Errorf("%s doesn't have its own source files", module.Path)
+		}
 	}
 }
 
@@ -47,6 +101,14 @@ func TestNewProject(t *testing.T) {
 			wantBuilder: "Maven",
 			wantErr: false,
 		},
+		{
+			name: "Gradle Kotlin DSL",
+			args: args{
+				path: filepath.Join("..", "test", "fixtures", "gradle-kotlin-dsl-project"),
+			},
+			wantBuilder: "Gradle",
+			wantErr:     false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -97,6 +159,11 @@ func TestProject_Packages(t *testing.T) {
 			projectPath: filepath.Join("..", "test", "fixtures", "sbt-project"),
 			want:        []string{"com.example"},
 		},
+		{
+			name:        "Kotlin",
+			projectPath: filepath.Join("..", "test", "fixtures", "kotlin-project"),
+			want:        []string{"com.gitlab.security_products.object.util", "com.gitlab.security_products.tests"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -106,7 +173,9 @@ func TestProject_Packages(t *testing.T) {
 				return
 			}
 
-			if got := p.Packages(); !reflect.DeepEqual(got, tt.want) {
+			got := p.Packages()
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
 				absPath, err := filepath.Abs(tt.projectPath)
 				if err != nil {
 					cur, _ := os.Getwd()
@@ -147,6 +216,26 @@ func TestProject_GetSourcePathRelativeToProject(t *testing.T) {
 			wantResult:   "src/main/scala/com/example/Main.scala",
 			wantErr:      false,
 		},
+		{
+			name:         "Kotlin",
+			projectPath:  filepath.Join("..", "test", "fixtures", "kotlin-project"),
+			reportedPath: "com/gitlab/security_products/tests/App.kt",
+			wantResult:   "src/main/kotlin/com/gitlab/security_products/tests/App.kt",
+			wantErr:      false,
+		},
+		{
+			name:         "Kotlin package not matching directory",
+			projectPath:  filepath.Join("..", "test", "fixtures", "kotlin-project"),
+			reportedPath: "com/gitlab/security_products/object/util/Tokens.kt",
+			wantResult:   "src/main/kotlin/util/Tokens.kt",
+			wantErr:      false,
+		},
+		{
+			name:         "Missing",
+			projectPath:  filepath.Join("..", "test", "fixtures", "kotlin-project"),
+			reportedPath: "com/gitlab/security_products/tests/Missing.kt",
+			wantErr:      true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -173,3 +262,65 @@ func TestProject_GetSourcePathRelativeToProject(t *testing.T) {
 		})
 	}
 }
+
+func TestProject_RestrictToSourceFiles(t *testing.T) {
+	p, err := newProject(filepath.Join("..", "test", "fixtures", "maven-project"))
+	if err != nil {
+		t.Fatalf("%s\n", err.Error())
+	}
+
+	mainFile := filepath.Join("src", "main", "java", "com", "gitlab", "security_products", "tests", "App.java")
+	if !p.HasSourceFile(mainFile) {
+		t.Errorf("Project.HasSourceFile(%s) = false, want true", mainFile)
+	}
+
+	if p.HasSourceFile("Missing.java") {
+		t.Errorf("Project.HasSourceFile(Missing.java) = true, want false")
+	}
+
+	p.RestrictToSourceFiles([]string{"Missing.java"})
+	if got := p.Packages(); len(got) != 0 {
+		t.Errorf("Project.Packages() = %v after restricting to a missing file, want none", got)
+	}
+
+	p.RestrictToSourceFiles([]string{mainFile})
+	if got, want := p.Packages(), []string{"com.gitlab.security_products.tests"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Project.Packages() = %v, want %v", got, want)
+	}
+}
+
+func TestKotlinPackage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "Simple",
+			content: "package com.example.app\n\nfun main() {}\n",
+			want:    "com.example.app",
+		},
+		{
+			name:    "File annotation and semicolon",
+			content: "@file:JvmName(\"Main\")\n\npackage com.example;\n",
+			want:    "com.example",
+		},
+		{
+			name:    "Backticked identifiers",
+			content: "package com.`when`.`fun`.app\n",
+			want:    "com.when.fun.app",
+		},
+		{
+			name:    "Default package",
+			content: "// this package is great\nfun main() {}\n",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kotlinPackage([]byte(tt.content)); got != tt.want {
+				t.Errorf("kotlinPackage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+// Package sarif translates SpotBugs bug instances into a SARIF 2.1.0 log.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/common/v2/issue"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/metadata"
+)
+
+const (
+	schemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	version   = "2.1.0"
+
+	// srcRootID is the base URI identifier used for paths relative to the repository root.
+	srcRootID = "%SRCROOT%"
+
+	// fingerprintInstanceHash is the partial fingerprint key of the SpotBugs instance hash.
+	fingerprintInstanceHash = "spotBugsInstanceHash/v1"
+	// fingerprintTrackingKey is the partial fingerprint key of the line-shift resilient tracking key.
+	fingerprintTrackingKey = "spotBugsTrackingKey/v1"
+
+	cweName = "CWE"
+	cweURI  = "https://cwe.mitre.org/"
+)
+
+// Log is the root object of a SARIF file.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run describes a single run of the analyzer.
+type Run struct {
+	Tool               Tool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]ArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Taxonomies         []ToolComponent             `json:"taxonomies,omitempty"`
+	Results            []Result                    `json:"results"`
+}
+
+// Tool describes the analysis tool.
+type Tool struct {
+	Driver ToolComponent `json:"driver"`
+}
+
+// ToolComponent describes the tool driver, or a taxonomy such as CWE.
+type ToolComponent struct {
+	Name           string  `json:"name"`
+	Organization   string  `json:"organization,omitempty"`
+	Version        string  `json:"version,omitempty"`
+	InformationURI string  `json:"informationUri,omitempty"`
+	Rules          []Rule  `json:"rules,omitempty"`
+	Taxa           []Taxon `json:"taxa,omitempty"`
+	// Properties are the settings of the analysis, for the tool driver.
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// Rule describes a SpotBugs bug pattern.
+type Rule struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name,omitempty"`
+	ShortDescription *Message       `json:"shortDescription,omitempty"`
+	FullDescription  *Message       `json:"fullDescription,omitempty"`
+	HelpURI          string         `json:"helpUri,omitempty"`
+	Relationships    []Relationship `json:"relationships,omitempty"`
+}
+
+// Taxon is an entry of a taxonomy, such as a CWE weakness.
+type Taxon struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri,omitempty"`
+}
+
+// Relationship links a rule to a taxon.
+type Relationship struct {
+	Target ReportingDescriptorReference `json:"target"`
+	Kinds  []string                     `json:"kinds"`
+}
+
+// ReportingDescriptorReference references a taxon of a tool component.
+type ReportingDescriptorReference struct {
+	ID            string                 `json:"id"`
+	ToolComponent ToolComponentReference `json:"toolComponent"`
+}
+
+// ToolComponentReference references a tool component by name.
+type ToolComponentReference struct {
+	Name string `json:"name"`
+}
+
+// Message is a plain text message.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result describes a bug instance.
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Suppressions        []Suppression     `json:"suppressions,omitempty"`
+}
+
+// Suppression tells that a result has been reviewed and shouldn't be reported as new.
+type Suppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// Location describes where a bug instance is, both in the source files and in the code structure.
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []LogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// PhysicalLocation is a region of a source file.
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation is the location of a file, relative to a base URI.
+type ArtifactLocation struct {
+	URI       string `json:"uri,omitempty"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// Region is a range of lines.
+type Region struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// LogicalLocation is a class or method.
+type LogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// NewLog builds a SARIF log from bug instances whose source paths are relative to the repository root.
+// The settings of the analysis are reported as properties of the tool driver.
+func NewLog(bugInstances []instance.Instance, settings map[string]string) *Log {
+	rules, ruleIndexes := newRules(bugInstances)
+
+	results := make([]Result, len(bugInstances))
+	for i, bug := range bugInstances {
+		results[i] = newResult(bug, ruleIndexes[bug.Type])
+	}
+
+	run := Run{
+		Tool: Tool{
+			Driver: ToolComponent{
+				Name:           metadata.ReportScanner.Name,
+				Organization:   metadata.ReportScanner.Vendor.Name,
+				Version:        metadata.ReportScanner.Version,
+				InformationURI: metadata.ReportScanner.URL,
+				Rules:          rules,
+				Properties:     settings,
+			},
+		},
+		OriginalURIBaseIDs: map[string]ArtifactLocation{
+			srcRootID: {},
+		},
+		Results: results,
+	}
+
+	if taxa := newCWETaxa(bugInstances); len(taxa) > 0 {
+		run.Taxonomies = []ToolComponent{
+			{
+				Name:           cweName,
+				Organization:   "MITRE",
+				InformationURI: cweURI,
+				Taxa:           taxa,
+			},
+		}
+	}
+
+	return &Log{
+		Schema:  schemaURI,
+		Version: version,
+		Runs:    []Run{run},
+	}
+}
+
+// Write encodes the SARIF log as indented JSON.
+func (l *Log) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// newRules returns one rule per bug type, sorted by type, and the index of each rule.
+func newRules(bugInstances []instance.Instance) ([]Rule, map[string]int) {
+	byType := make(map[string]instance.Instance)
+	for _, bug := range bugInstances {
+		if _, ok := byType[bug.Type]; !ok {
+			byType[bug.Type] = bug
+		}
+	}
+
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	rules := make([]Rule, len(types))
+	indexes := make(map[string]int, len(types))
+	for i, t := range types {
+		rules[i] = newRule(byType[t])
+		indexes[t] = i
+	}
+
+	return rules, indexes
+}
+
+// newRule returns the rule describing the type of the bug instance.
+func newRule(bug instance.Instance) Rule {
+	rule := Rule{
+		ID:               bug.Type,
+		Name:             bug.Abbrev,
+		ShortDescription: &Message{Text: bug.ShortMessage},
+	}
+
+	// The first identifier is always the bug type, pointing to its documentation.
+	if identifiers := bug.Identifiers(); len(identifiers) > 0 {
+		rule.HelpURI = identifiers[0].URL
+	}
+
+	if bug.CWEID != 0 {
+		rule.Relationships = []Relationship{
+			{
+				Target: ReportingDescriptorReference{
+					ID:            strconv.Itoa(bug.CWEID),
+					ToolComponent: ToolComponentReference{Name: cweName},
+				},
+				Kinds: []string{"superset"},
+			},
+		}
+	}
+
+	return rule
+}
+
+// newCWETaxa returns the CWE weaknesses referenced by the bug instances, sorted by ID.
+func newCWETaxa(bugInstances []instance.Instance) []Taxon {
+	seen := make(map[int]bool)
+	ids := make([]int, 0)
+	for _, bug := range bugInstances {
+		if bug.CWEID != 0 && !seen[bug.CWEID] {
+			seen[bug.CWEID] = true
+			ids = append(ids, bug.CWEID)
+		}
+	}
+	sort.Ints(ids)
+
+	taxa := make([]Taxon, len(ids))
+	for i, id := range ids {
+		taxa[i] = Taxon{
+			ID:      strconv.Itoa(id),
+			HelpURI: issue.CWEIdentifier(id).URL,
+		}
+	}
+
+	return taxa
+}
+
+// newResult returns the result corresponding to the bug instance.
+func newResult(bug instance.Instance, ruleIndex int) Result {
+	location := Location{
+		PhysicalLocation: &PhysicalLocation{
+			ArtifactLocation: ArtifactLocation{
+				URI:       bug.SourceLine.SourcePath,
+				URIBaseID: srcRootID,
+			},
+		},
+	}
+
+	if bug.SourceLine.Start > 0 {
+		location.PhysicalLocation.Region = &Region{
+			StartLine: bug.SourceLine.Start,
+			EndLine:   bug.SourceLine.End,
+		}
+	}
+
+	if bug.Method.Name != "" {
+		location.LogicalLocations = []LogicalLocation{
+			{
+				Name:               bug.Method.Name,
+				FullyQualifiedName: fmt.Sprintf("%s.%s", bug.Class.Name, bug.Method.Name),
+				Kind:               "function",
+			},
+		}
+	} else if bug.Class.Name != "" {
+		location.LogicalLocations = []LogicalLocation{
+			{
+				FullyQualifiedName: bug.Class.Name,
+				Kind:               "type",
+			},
+		}
+	}
+
+	result := Result{
+		RuleID:    bug.Type,
+		RuleIndex: ruleIndex,
+		Level:     level(bug.Severity()),
+		Message:   Message{Text: bug.LongMessage},
+		Locations: []Location{location},
+	}
+
+	fingerprints := make(map[string]string)
+	if bug.InstanceHash != "" {
+		fingerprints[fingerprintInstanceHash] = bug.InstanceHash
+	}
+	if key := bug.TrackingKey(); key != "" {
+		fingerprints[fingerprintTrackingKey] = key
+	}
+	if len(fingerprints) > 0 {
+		result.PartialFingerprints = fingerprints
+	}
+
+	if bug.Justification != "" {
+		result.Suppressions = []Suppression{
+			{Kind: "inSource", Justification: bug.Justification},
+		}
+	} else if bug.Suppressed {
+		result.Suppressions = []Suppression{
+			{Kind: "external", Justification: "Present in the baseline"},
+		}
+	}
+
+	return result
+}
+
+// level maps the normalized severity of an issue to a SARIF result level.
+func level(severity issue.SeverityLevel) string {
+	switch severity {
+	case issue.SeverityLevelCritical, issue.SeverityLevelHigh:
+		return "error"
+	case issue.SeverityLevelMedium:
+		return "warning"
+	case issue.SeverityLevelLow:
+		return "note"
+	}
+	return "none"
+}
@@ -0,0 +1,105 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+)
+
+func newInstance(bugType string, cweID int, rank int, hash string, path string, start int) instance.Instance {
+	bug := instance.Instance{
+		Type:         bugType,
+		CWEID:        cweID,
+		Rank:         rank,
+		InstanceHash: hash,
+		ShortMessage: "Short " + bugType,
+		LongMessage:  "Long " + bugType,
+	}
+	bug.Class.Name = "com.gitlab.security_products.tests.App"
+	bug.Method.Name = "run"
+	bug.SourceLine = instance.SourceLine{Start: start, End: start, SourcePath: path}
+	return bug
+}
+
+func TestNewLog(t *testing.T) {
+	bugInstances := []instance.Instance{
+		newInstance("PREDICTABLE_RANDOM", 330, 12, "818bf5dacb291e15d9e6dc3c5ac32178", "app/src/main/java/App.java", 47),
+		newInstance("CIPHER_INTEGRITY", 353, 3, "e6449b89335daf53c0db4c0219bc1634", "app/src/main/java/App.java", 29),
+		newInstance("PREDICTABLE_RANDOM", 330, 18, "", "lib/src/main/java/Lib.java", 0),
+	}
+
+	log := NewLog(bugInstances, map[string]string{"effort": "max"})
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	require.Equal(t, map[string]string{"effort": "max"}, run.Tool.Driver.Properties)
+
+	// One rule per type, sorted by type.
+	rules := run.Tool.Driver.Rules
+	require.Len(t, rules, 2)
+	require.Equal(t, "CIPHER_INTEGRITY", rules[0].ID)
+	require.Equal(t, "PREDICTABLE_RANDOM", rules[1].ID)
+	require.Equal(t, "https://find-sec-bugs.github.io/bugs.htm#CIPHER_INTEGRITY", rules[0].HelpURI)
+	require.Equal(t, []Relationship{
+		{
+			Target: ReportingDescriptorReference{ID: "353", ToolComponent: ToolComponentReference{Name: "CWE"}},
+			Kinds:  []string{"superset"},
+		},
+	}, rules[0].Relationships)
+
+	// CWE taxonomy.
+	require.Len(t, run.Taxonomies, 1)
+	require.Equal(t, []Taxon{
+		{ID: "330", HelpURI: "https://cwe.mitre.org/data/definitions/330.html"},
+		{ID: "353", HelpURI: "https://cwe.mitre.org/data/definitions/353.html"},
+	}, run.Taxonomies[0].Taxa)
+
+	// Results keep the order of the bug instances.
+	require.Len(t, run.Results, 3)
+	require.Equal(t, Result{
+		RuleID:    "PREDICTABLE_RANDOM",
+		RuleIndex: 1,
+		Level:     "warning",
+		Message:   Message{Text: "Long PREDICTABLE_RANDOM"},
+		Locations: []Location{
+			{
+				PhysicalLocation: &PhysicalLocation{
+					ArtifactLocation: ArtifactLocation{URI: "app/src/main/java/App.java", URIBaseID: "%SRCROOT%"},
+					Region:           &Region{StartLine: 47, EndLine: 47},
+				},
+				LogicalLocations: []LogicalLocation{
+					{
+						Name:               "run",
+						FullyQualifiedName: "com.gitlab.security_products.tests.App.run",
+						Kind:               "function",
+					},
+				},
+			},
+		},
+		PartialFingerprints: map[string]string{"spotBugsInstanceHash/v1": "818bf5dacb291e15d9e6dc3c5ac32178"},
+	}, run.Results[0])
+	require.Equal(t, 0, run.Results[1].RuleIndex)
+	require.Equal(t, "error", run.Results[1].Level)
+	require.Equal(t, "note", run.Results[2].Level)
+	require.Nil(t, run.Results[2].PartialFingerprints)
+	require.Nil(t, run.Results[2].Locations[0].PhysicalLocation.Region)
+}
+
+func TestLog_Write(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewLog(nil, nil).Write(&buf))
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, "2.1.0", got["version"])
+	require.Equal(t, "https://json.schemastore.org/sarif-2.1.0.json", got["$schema"])
+
+	runs := got["runs"].([]interface{})
+	require.Len(t, runs, 1)
+	require.Equal(t, []interface{}{}, runs[0].(map[string]interface{})["results"])
+	require.NotContains(t, runs[0], "taxonomies")
+}
@@ -0,0 +1,60 @@
+package sdkman
+
+import (
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/jdk"
+)
+
+// localJDKs are the JDKs found in each local JDKs directory, guarded by installMutex.
+var localJDKs = make(map[string][]jdk.JDK)
+
+// returns whether JDKs are taken from a local directory instead of being installed with SDKMAN
+func usesLocalJDKs(c *cli.Context) bool {
+	return c.String(FlagJdkDir) != ""
+}
+
+// returns the home directory of the local JDK of the given major version, or of the lowest version that is at
+// least the given one if exact is false. JDK archives are unpacked in the temporary directory.
+func localJavaHome(c *cli.Context, major int, exact bool) (string, error) {
+	installMutex.Lock()
+	defer installMutex.Unlock()
+
+	dir := c.String(FlagJdkDir)
+	jdks, ok := localJDKs[dir]
+	if !ok {
+		var err error
+		jdks, err = jdk.Discover(dir)
+		if err != nil {
+			return "", err
+		}
+		localJDKs[dir] = jdks
+
+		for _, j := range jdks {
+			log.Debugf("Found %s.\n", j)
+		}
+	}
+
+	find := jdk.Select
+	if exact {
+		find = jdk.Find
+	}
+
+	j, err := find(jdks, major)
+	if err != nil {
+		log.Errorf("Error: Java %d isn't available in %s: %s\n", major, dir, err.Error())
+		return "", err
+	}
+
+	home, err := j.Home(filepath.Join(os.TempDir(), "spotbugs-jdks"))
+	if err != nil {
+		return "", err
+	}
+
+	log.Debugf("Using %s for Java %d.\n", home, major)
+	return home, nil
+}
@@ -2,8 +2,13 @@ package sdkman
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -18,35 +23,172 @@ const (
 	FlagJava8Version = "java8Version"
 	// FlagJava11Version is the name of spotbug's cli java 11 version argument
 	FlagJava11Version = "java11Version"
+	// FlagJava17Version is the name of spotbug's cli java 17 version argument
+	FlagJava17Version = "java17Version"
+	// FlagJava21Version is the name of spotbug's cli java 21 version argument
+	FlagJava21Version = "java21Version"
 	// FlagSdkmanDir is the name of spotbug's cli sdkman argument
 	FlagSdkmanDir = "sdkmanDir"
+	// FlagJdkDir is the name of spotbug's cli local JDKs directory argument
+	FlagJdkDir = "jdkDir"
 )
 
-// SetupSystemJava sets up the system so that SpotBugs and it's dependencies (e.g. Maven) use the same Java
-func SetupSystemJava(c *cli.Context) {
+// installMutex serializes the installations of Java versions.
+var installMutex sync.Mutex
+
+// identifierMatcher matches the SDKMAN identifiers that can be installed, like 11.0.8.hs-adpt or 21.0.1-tem.
+var identifierMatcher = regexp.MustCompile(`^[0-9]+[.-][0-9A-Za-z._+-]*$`)
+
+// javaVersions maps the supported major Java versions to the flag defining the SDKMAN identifier of
+// the release installed for them. Supporting a new LTS release only takes a new flag and entry here.
+var javaVersions = []struct {
+	major string
+	flag  string
+}{
+	{major: "8", flag: FlagJava8Version},
+	{major: "11", flag: FlagJava11Version},
+	{major: "17", flag: FlagJava17Version},
+	{major: "21", flag: FlagJava21Version},
+}
+
+// SupportedJavaVersions are the major Java versions that can be selected.
+var SupportedJavaVersions = supportedJavaVersions()
+
+func supportedJavaVersions() []string {
+	versions := make([]string, 0, len(javaVersions))
+	for _, v := range javaVersions {
+		versions = append(versions, v.major)
+	}
+
+	return versions
+}
+
+// IsSupportedJavaVersion returns true if the version is a supported major Java version or an exact
+// SDKMAN identifier, like 17.0.9-tem.
+func IsSupportedJavaVersion(version string) bool {
+	return majorVersionFlag(version) != "" || isIdentifier(version)
+}
+
+// CompatibleJavaVersion returns the lowest supported major Java version able to build code requiring the given
+// version, or the highest supported one if none is. SDKMAN identifiers are returned as is.
+func CompatibleJavaVersion(version string) string {
+	if isIdentifier(version) {
+		return version
+	}
+
+	required, err := strconv.Atoi(version)
+	if err != nil {
+		return version
+	}
+
+	for _, v := range javaVersions {
+		if major, _ := strconv.Atoi(v.major); major >= required {
+			return v.major
+		}
+	}
+
+	return javaVersions[len(javaVersions)-1].major
+}
+
+// SetupSystemJava sets up the system so that SpotBugs and it's dependencies (e.g. Maven) use the same Java.
+// It fails if the selected Java version is not supported or can't be installed.
+func SetupSystemJava(c *cli.Context) error {
 	if usesCustomJavaPath(c) {
-		return
+		return nil
+	}
+
+	if usesLocalJDKs(c) {
+		_, err := localJavaHome(c, majorVersion(c.String(FlagJavaVersion)), true)
+		return err
+	}
+
+	javaVersion, err := selectedSystemJava(c)
+	if err != nil {
+		return err
+	}
+
+	return runSdk(c, javaVersion,
+		`([ -d "$SDKMAN_DIR/candidates/java/$1" ] || sdk install java "$1") && sdk default java "$1"`)
+}
+
+// InstallJava installs the given major Java version or SDKMAN identifier if needed, without changing the
+// default Java, and returns its home directory. When a local JDKs directory is set, the JDK of the same major
+// version is used instead. It returns an empty string when a custom Java is used.
+func InstallJava(c *cli.Context, version string) (string, error) {
+	if usesCustomJavaPath(c) {
+		return "", nil
+	}
+
+	if usesLocalJDKs(c) {
+		return localJavaHome(c, majorVersion(version), true)
+	}
+
+	identifier, err := javaIdentifier(c, version)
+	if err != nil {
+		return "", err
 	}
 
-	sdkmanDir := c.String(FlagSdkmanDir)
-	javaVersion := selectedSystemJava(c)
+	// Projects are analyzed concurrently, install each version once.
+	installMutex.Lock()
+	defer installMutex.Unlock()
 
+	home := filepath.Join(c.String(FlagSdkmanDir), "candidates", "java", identifier)
+	if _, err := os.Stat(home); err == nil {
+		return home, nil
+	}
+
+	// Answer no when asked whether the new version should be the default one.
+	if err := runSdk(c, identifier, `sdkman_auto_answer=false && echo n | sdk install java "$1"`); err != nil {
+		return "", err
+	}
+
+	return home, nil
+}
+
+// JavaPathFor returns the path of a java executable able to read the class files of the given major Java
+// version: the default one if it's recent enough, or the lowest supported version that is, installed if needed.
+func JavaPathFor(c *cli.Context, required int) (string, error) {
+	if usesCustomJavaPath(c) || required <= majorVersion(c.String(FlagJavaVersion)) {
+		return JavaPath(c), nil
+	}
+
+	if usesLocalJDKs(c) {
+		home, err := localJavaHome(c, required, false)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "bin", "java"), nil
+	}
+
+	version := CompatibleJavaVersion(strconv.Itoa(required))
+	home, err := InstallJava(c, version)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debugf("Using Java %s to read Java %d class files.\n", version, required)
+	return filepath.Join(home, "bin", "java"), nil
+}
+
+// runs a sdk script with the Java identifier as its first argument, so that it's never interpreted by the shell
+func runSdk(c *cli.Context, identifier, script string) error {
 	cmd := exec.Command(
 		"/bin/bash",
 		"-c",
-		fmt.Sprintf("source %[1]s/bin/sdkman-init.sh"+
-			// can exit 1 if already installed
-			" && (sdk list java | grep -qv \"installed.*%[2]s\" || sdk install java %[2]s)"+
-			" && sdk default java %[2]s", sdkmanDir, javaVersion))
+		`source "$SDKMAN_DIR/bin/sdkman-init.sh" && `+script,
+		"sdk",
+		identifier)
+	cmd.Env = append(os.Environ(), "SDKMAN_DIR="+c.String(FlagSdkmanDir))
 	output, err := cmd.CombinedOutput()
 
 	log.Debugf("%s\n%s", cmd.String(), output)
 
 	if err != nil {
-		log.Warnf("Failed to set system Java: %s\n", err.Error())
-
-		return
+		log.Errorf("%s\n", output)
+		return fmt.Errorf("failed to install Java %s: %v", identifier, err)
 	}
+
+	return nil
 }
 
 // JavaPath determines the path to the java executable
@@ -55,6 +197,15 @@ func JavaPath(c *cli.Context) string {
 		return c.String(FlagJavaPath)
 	}
 
+	if usesLocalJDKs(c) {
+		home, err := localJavaHome(c, majorVersion(c.String(FlagJavaVersion)), true)
+		if err != nil {
+			log.Warnf("Using java from the PATH: %s\n", err)
+			return "java"
+		}
+		return filepath.Join(home, "bin", "java")
+	}
+
 	return filepath.FromSlash(fmt.Sprintf("%s/candidates/java/current/bin/java", c.String(FlagSdkmanDir)))
 }
 
@@ -63,17 +214,53 @@ func usesCustomJavaPath(c *cli.Context) bool {
 	return c.String(FlagJavaPath) != "java" && c.String(FlagJavaPath) != ""
 }
 
-// determine the version of the selected Java to use
-func selectedSystemJava(c *cli.Context) string {
-	switch c.String(FlagJavaVersion) {
-	case "11":
-		return c.String(FlagJava11Version)
-	case "8":
-		return c.String(FlagJava8Version)
-	default:
-		log.Warnf(
-			"Java version %s is not supported. Valid values are 8, 11. Using Java 8.\n",
-			c.String(FlagJavaVersion))
-		return c.String(FlagJava8Version)
+// determine the SDKMAN identifier of the selected Java to use
+func selectedSystemJava(c *cli.Context) (string, error) {
+	return javaIdentifier(c, c.String(FlagJavaVersion))
+}
+
+// determine the SDKMAN identifier of a major Java version or SDKMAN identifier
+func javaIdentifier(c *cli.Context, version string) (string, error) {
+	if flag := majorVersionFlag(version); flag != "" {
+		if c.String(flag) == "" {
+			return "", fmt.Errorf("no SDKMAN identifier is defined for Java %s", version)
+		}
+		return c.String(flag), nil
 	}
+
+	if isIdentifier(version) {
+		return version, nil
+	}
+
+	return "", fmt.Errorf("Java version %s is not supported, valid values are %s or an SDKMAN identifier",
+		version, strings.Join(SupportedJavaVersions, ", "))
+}
+
+// returns the major version of a major Java version or SDKMAN identifier, or 0 if it has none
+func majorVersion(version string) int {
+	end := strings.IndexFunc(version, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(version)
+	}
+
+	major, _ := strconv.Atoi(version[:end])
+	return major
+}
+
+// returns the flag defining the SDKMAN identifier of a major Java version, or an empty string
+// if the major version is not supported
+func majorVersionFlag(version string) string {
+	for _, v := range javaVersions {
+		if v.major == version {
+			return v.flag
+		}
+	}
+
+	return ""
+}
+
+// returns whether the version is an exact SDKMAN identifier rather than a major version, like
+// 11.0.8.hs-adpt or 21.0.1-tem
+func isIdentifier(version string) bool {
+	return identifierMatcher.MatchString(version)
 }
@@ -0,0 +1,182 @@
+package sdkman
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/testutil"
+)
+
+func TestSelectedSystemJava(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "8", want: "8.0.265.hs-adpt"},
+		{version: "11", want: "11.0.8.hs-adpt"},
+		{version: "17", want: "17.0.9-tem"},
+		{version: "21", want: "21.0.1-tem"},
+		{version: "21.0.2-graalce", want: "21.0.2-graalce"},
+		{version: "25", wantErr: true},
+		{version: "", wantErr: true},
+		{version: "17.0.9-tem; rm -rf /", wantErr: true},
+		{version: "17.0.9-$(id)", wantErr: true},
+		{version: "17.0.9-tem\nid", wantErr: true},
+		{version: "-tem", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			set := flag.NewFlagSet("sdkman", 0)
+			set.String(FlagJavaVersion, tt.version, "")
+			set.String(FlagJava8Version, "8.0.265.hs-adpt", "")
+			set.String(FlagJava11Version, "11.0.8.hs-adpt", "")
+			set.String(FlagJava17Version, "17.0.9-tem", "")
+			set.String(FlagJava21Version, "21.0.1-tem", "")
+			c := cli.NewContext(nil, set, nil)
+
+			got, err := selectedSystemJava(c)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error but got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Wrong Java version. Expected %s but got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetupSystemJava_CustomJavaPath(t *testing.T) {
+	set := flag.NewFlagSet("sdkman", 0)
+	set.String(FlagJavaPath, "/opt/jdk/bin/java", "")
+	set.String(FlagJavaVersion, "7", "")
+	c := cli.NewContext(nil, set, nil)
+
+	// A custom Java is used as is, whatever the selected version.
+	if err := SetupSystemJava(c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompatibleJavaVersion(t *testing.T) {
+	for version, want := range map[string]string{
+		"7":          "8",
+		"8":          "8",
+		"9":          "11",
+		"15":         "17",
+		"21":         "21",
+		"25":         "21",
+		"17.0.9-tem": "17.0.9-tem",
+	} {
+		if got := CompatibleJavaVersion(version); got != want {
+			t.Errorf("Wrong compatible version of %s. Expected %s but got %s", version, want, got)
+		}
+	}
+}
+
+func TestJavaPathFor(t *testing.T) {
+	set := flag.NewFlagSet("sdkman", 0)
+	set.String(FlagJavaPath, "java", "")
+	set.String(FlagJavaVersion, "11.0.8.hs-adpt", "")
+	set.String(FlagJava17Version, "17.0.9-tem", "")
+	set.String(FlagSdkmanDir, "/usr/local/sdkman", "")
+	c := cli.NewContext(nil, set, nil)
+
+	// The default Java reads class files up to its own version.
+	for _, version := range []int{0, 8, 11} {
+		got, err := JavaPathFor(c, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "/usr/local/sdkman/candidates/java/current/bin/java"; got != want {
+			t.Errorf("Wrong java path for Java %d. Expected %s but got %s", version, want, got)
+		}
+	}
+}
+
+func TestInstallJava_Sdkman(t *testing.T) {
+	dir, cleanup := testutil.TempDir(t, "sdkman")
+	defer cleanup()
+
+	// The sdk function records its arguments.
+	testutil.WriteFiles(t, dir, map[string]string{
+		"bin/sdkman-init.sh": `sdk() { printf '%s\n' "$@" > "$SDKMAN_DIR/arguments"; }`,
+	})
+
+	// Identifiers defined by flags aren't validated, but they are never interpreted by the shell.
+	identifier := "17.0.9-tem$(touch injected)"
+	set := flag.NewFlagSet("sdkman", 0)
+	set.String(FlagJavaPath, "java", "")
+	set.String(FlagJava17Version, identifier, "")
+	set.String(FlagSdkmanDir, dir, "")
+	c := cli.NewContext(nil, set, nil)
+
+	home, err := InstallJava(c, "17")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "candidates", "java", identifier); home != want {
+		t.Errorf("Wrong Java home. Expected %s but got %s", want, home)
+	}
+
+	arguments, err := ioutil.ReadFile(filepath.Join(dir, "arguments"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "install\njava\n" + identifier + "\n"; string(arguments) != want {
+		t.Errorf("Wrong sdk arguments. Expected %q but got %q", want, arguments)
+	}
+	if _, err := os.Stat("injected"); err == nil {
+		os.Remove("injected")
+		t.Error("The identifier was interpreted by the shell")
+	}
+}
+
+func TestInstallJava_LocalJDKs(t *testing.T) {
+	dir, cleanup := testutil.TempDir(t, "jdks")
+	defer cleanup()
+
+	testutil.WriteFiles(t, dir, map[string]string{"jdk8/bin/java": "", "jdk17/bin/java": ""})
+
+	set := flag.NewFlagSet("sdkman", 0)
+	set.String(FlagJavaPath, "java", "")
+	set.String(FlagJavaVersion, "8", "")
+	set.String(FlagJdkDir, dir, "")
+	c := cli.NewContext(nil, set, nil)
+
+	// SDKMAN isn't used with local JDKs.
+	if err := SetupSystemJava(c); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := JavaPath(c), filepath.Join(dir, "jdk8", "bin", "java"); got != want {
+		t.Errorf("Wrong java path. Expected %s but got %s", want, got)
+	}
+
+	home, err := InstallJava(c, "17.0.9-tem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "jdk17"); home != want {
+		t.Errorf("Wrong Java home. Expected %s but got %s", want, home)
+	}
+
+	if got, err := JavaPathFor(c, 11); err != nil || got != filepath.Join(dir, "jdk17", "bin", "java") {
+		t.Errorf("Wrong java path for Java 11 class files: %s (%v)", got, err)
+	}
+
+	_, err = InstallJava(c, "11")
+	if err == nil || !strings.Contains(err.Error(), "found: Java 8") {
+		t.Errorf("Expected an error listing the local JDKs but got %v", err)
+	}
+}
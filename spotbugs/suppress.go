@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+)
+
+const (
+	suppressionModeDrop     = "drop"
+	suppressionModeSuppress = "suppress"
+)
+
+// validateSuppressionMode returns an error if the suppression mode isn't supported.
+func validateSuppressionMode(c *cli.Context) error {
+	switch c.String(flagSuppressionMode) {
+	case "", suppressionModeDrop, suppressionModeSuppress:
+		return nil
+	default:
+		return fmt.Errorf(
+			"suppression mode %s is not supported. Valid values are %s, %s",
+			c.String(flagSuppressionMode), suppressionModeDrop, suppressionModeSuppress)
+	}
+}
+
+// suppressInSource drops the bug instances suppressed by a spotbugs-ignore comment of their source file, or marks
+// them as suppressed. Comments without justification are ignored with a warning. Bug instances annotated with
+// @SuppressFBWarnings aren't reported by SpotBugs in the first place.
+func suppressInSource(c *cli.Context, repositoryPath string, bugInstances []instance.Instance) []instance.Instance {
+	unjustified := instance.SuppressInSource(repositoryPath, bugInstances)
+	for _, s := range unjustified {
+		log.Warnf("Ignoring spotbugs-ignore comment of %s at %s:%d, a justification is required.\n",
+			strings.Join(s.Types, ", "), s.Path, s.Line)
+	}
+
+	suppress := c.String(flagSuppressionMode) == suppressionModeSuppress
+
+	result := make([]instance.Instance, 0, len(bugInstances))
+	count := 0
+	for _, bug := range bugInstances {
+		if bug.Justification != "" {
+			count++
+			if !suppress {
+				continue
+			}
+			bug.Suppressed = true
+		}
+		result = append(result, bug)
+	}
+
+	if count > 0 {
+		log.Infof("%d of %d findings are suppressed in the source code\n", count, len(bugInstances))
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/instance"
+)
+
+func TestSuppressInSource(t *testing.T) {
+	repositoryPath, err := ioutil.TempDir("", "test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryPath)
+
+	source := "class App {\n    // spotbugs-ignore: PREDICTABLE_RANDOM only used for tests\n    Random r = new Random();\n}\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryPath, "App.java"), []byte(source), 0644))
+
+	ignored := instance.Instance{Type: "PREDICTABLE_RANDOM"}
+	ignored.SourceLine = instance.SourceLine{Start: 3, End: 3, SourcePath: "App.java"}
+	fresh := instance.Instance{Type: "SQL_INJECTION_JDBC"}
+	fresh.SourceLine = instance.SourceLine{Start: 3, End: 3, SourcePath: "App.java"}
+
+	tests := []struct {
+		mode string
+		want []instance.Instance
+	}{
+		{
+			mode: suppressionModeDrop,
+			want: []instance.Instance{fresh},
+		},
+		{
+			mode: suppressionModeSuppress,
+			want: func() []instance.Instance {
+				suppressed := ignored
+				suppressed.Suppressed = true
+				suppressed.Justification = "only used for tests"
+				return []instance.Instance{suppressed, fresh}
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			set := flag.NewFlagSet("analyze", 0)
+			set.String(flagSuppressionMode, tt.mode, "")
+			c := cli.NewContext(nil, set, nil)
+
+			got := suppressInSource(c, repositoryPath, []instance.Instance{ignored, fresh})
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateSuppressionMode(t *testing.T) {
+	for mode, wantErr := range map[string]bool{"": false, "drop": false, "suppress": false, "ignore": true} {
+		set := flag.NewFlagSet("analyze", 0)
+		set.String(flagSuppressionMode, mode, "")
+		c := cli.NewContext(nil, set, nil)
+
+		err := validateSuppressionMode(c)
+		require.Equal(t, wantErr, err != nil, "mode %q", mode)
+	}
+}
@@ -0,0 +1,14 @@
+plugins {
+    java
+}
+
+group = "com.gitlab.security_products"
+version = "0.0.1"
+
+repositories {
+    mavenCentral()
+}
+
+dependencies {
+    testImplementation("junit:junit:4.12")
+}
@@ -0,0 +1,15 @@
+plugins {
+    application
+}
+
+repositories {
+    mavenCentral()
+}
+
+dependencies {
+    implementation(project(":lib"))
+}
+
+application {
+    mainClassName = "com.gitlab.security_products.app.App"
+}
@@ -0,0 +1,17 @@
+package com.gitlab.security_products.app;
+
+import java.util.Random;
+
+import com.gitlab.security_products.lib.Tokens;
+
+public class App {
+    public static void main(String[] args) {
+        System.out.println(Tokens.INSTANCE.next());
+    }
+
+    // This method triggers a findbugs issue with "SECURITY" category (needs findsecbugs plugin)
+    String generateSecretToken() {
+        Random r = new Random();
+        return Long.toHexString(r.nextLong());
+    }
+}
@@ -0,0 +1,7 @@
+plugins {
+    kotlin("jvm") version "1.4.10"
+}
+
+repositories {
+    mavenCentral()
+}
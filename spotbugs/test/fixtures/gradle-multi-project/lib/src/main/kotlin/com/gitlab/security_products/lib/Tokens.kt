@@ -0,0 +1,7 @@
+package com.gitlab.security_products.lib
+
+import java.util.Random
+
+object Tokens {
+    fun next(): Long = Random().nextLong()
+}
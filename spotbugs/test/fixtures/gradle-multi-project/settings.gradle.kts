@@ -0,0 +1,3 @@
+rootProject.name = "gradle-multi-project"
+
+include("app", "lib")
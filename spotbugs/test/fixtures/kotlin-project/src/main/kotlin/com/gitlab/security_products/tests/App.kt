@@ -0,0 +1,14 @@
+@file:JvmName("App")
+
+package com.gitlab.security_products.tests
+
+import java.util.Random
+import javax.crypto.Cipher
+
+fun insecureCypher(): Cipher = Cipher.getInstance("AES/ECB/NoPadding")
+
+fun generateSecretToken(): String = java.lang.Long.toHexString(Random().nextLong())
+
+fun main() {
+    println(generateSecretToken())
+}
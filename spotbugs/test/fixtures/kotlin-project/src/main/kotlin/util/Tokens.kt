@@ -0,0 +1,8 @@
+// The directory doesn't match the package, which Kotlin allows.
+package com.gitlab.security_products.`object`.util
+
+import java.util.Random
+
+object Tokens {
+    fun next(): Long = Random().nextLong()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/sdkman"
+)
+
+const (
+	// classMagic starts every class file.
+	classMagic = 0xCAFEBABE
+	// classVersionOffset is the major version of class files compiled for Java 1.0, Java versions add one.
+	classVersionOffset = 44
+)
+
+// selectToolchains selects the JDK running the builder of each project: the Java version it's configured with,
+// or the one required by its build files unless the Java version is explicitly set, or the default one.
+// Modules of a Maven reactor use the JDK of their root project.
+func selectToolchains(c *cli.Context, projects []project.Project) error {
+	detect := !c.IsSet(sdkman.FlagJavaVersion)
+	homes := make(map[string]string)
+
+	for i := range projects {
+		p := projects[i].BuiltBy()
+		home, ok := homes[p.Path]
+		if !ok {
+			version := javaVersion(p, detect)
+			if version == "" {
+				version = c.String(sdkman.FlagJavaVersion)
+			}
+
+			var err error
+			home, err = sdkman.InstallJava(c, version)
+			if err != nil {
+				log.Errorf("Error: Couldn't install Java %s for %s: %s\n", version, p.Path, err.Error())
+				return err
+			}
+			homes[p.Path] = home
+		}
+
+		p.SetJavaHome(home)
+		projects[i].SetJavaHome(home)
+	}
+
+	return nil
+}
+
+// javaVersion returns the Java version building the project: the one it's configured with, or the one required
+// by its build files if detect is true. An empty string selects the default Java version.
+func javaVersion(p *project.Project, detect bool) string {
+	if version := p.JavaVersion(); version != "" {
+		log.Infof("Using Java %s configured for %s.\n", version, p.Path)
+		return version
+	}
+
+	if !detect {
+		return ""
+	}
+
+	required, filename, err := p.DetectJavaVersion()
+	if err != nil {
+		log.Warnf("Couldn't detect the Java version of %s, using the default one: %s\n", p.Path, err)
+		return ""
+	}
+	if required == "" {
+		log.Debugf("No Java version found in the build files of %s, using the default one.\n", p.Path)
+		return ""
+	}
+
+	version := sdkman.CompatibleJavaVersion(required)
+	log.Infof("Detected Java %s in %s of %s, using Java %s.\n", required, filename, p.Path, version)
+	return version
+}
+
+// spotBugsJavaPath returns the path of a java executable able to read the class files of the targets.
+func spotBugsJavaPath(c *cli.Context, targets []string) (string, error) {
+	version, err := classFileVersion(targets)
+	if err != nil {
+		log.Warnf("Couldn't read the version of the class files, using the default Java: %s\n", err)
+		return sdkman.JavaPath(c), nil
+	}
+
+	return sdkman.JavaPathFor(c, version)
+}
+
+// classFileVersion returns the highest Java version of the class files of the directories and jars, or 0 if
+// there is none.
+func classFileVersion(targets []string) (int, error) {
+	highest := 0
+	keep := func(version int) {
+		if version > highest {
+			highest = version
+		}
+	}
+
+	for _, target := range targets {
+		err := filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case info.IsDir():
+				return nil
+			case strings.HasSuffix(path, ".class"):
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				keep(readClassVersion(f))
+			case strings.HasSuffix(path, ".jar"):
+				version, err := jarClassVersion(path)
+				if err != nil {
+					return err
+				}
+				keep(version)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return highest, nil
+}
+
+// jarClassVersion returns the highest Java version of the class files of a jar.
+func jarClassVersion(path string) (int, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	highest := 0
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".class") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return 0, err
+		}
+		if version := readClassVersion(rc); version > highest {
+			highest = version
+		}
+		rc.Close()
+	}
+
+	return highest, nil
+}
+
+// readClassVersion returns the Java version of a class file, or 0 if it isn't a valid class file.
+func readClassVersion(r io.Reader) int {
+	var header struct {
+		Magic uint32
+		Minor uint16
+		Major uint16
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil || header.Magic != classMagic {
+		return 0
+	}
+
+	return int(header.Major) - classVersionOffset
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/project"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/sdkman"
+	"gitlab.com/gitlab-org/security-products/analyzers/spotbugs/v2/testutil"
+)
+
+// classFile returns the header of a class file compiled for the given Java version.
+func classFile(version int) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, []uint32{classMagic, uint32(version + classVersionOffset)})
+	return b.Bytes()
+}
+
+func TestClassFileVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "classes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	classes := filepath.Join(dir, "classes", "acme")
+	require.NoError(t, os.MkdirAll(classes, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(classes, "App.class"), classFile(8), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(classes, "README.txt"), []byte("not a class"), 0644))
+
+	jar, err := os.Create(filepath.Join(dir, "lib.jar"))
+	require.NoError(t, err)
+	w := zip.NewWriter(jar)
+	f, err := w.Create("acme/Lib.class")
+	require.NoError(t, err)
+	_, err = f.Write(classFile(17))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, jar.Close())
+
+	got, err := classFileVersion([]string{filepath.Join(dir, "classes")})
+	require.NoError(t, err)
+	require.Equal(t, 8, got)
+
+	got, err = classFileVersion([]string{filepath.Join(dir, "classes"), filepath.Join(dir, "lib.jar")})
+	require.NoError(t, err)
+	require.Equal(t, 17, got)
+
+	got, err = classFileVersion([]string{filepath.Join(dir, "empty")})
+	require.Error(t, err)
+	require.Equal(t, 0, got)
+}
+
+func TestSelectToolchains(t *testing.T) {
+	repo, err := ioutil.TempDir("", "repository")
+	require.NoError(t, err)
+	defer os.RemoveAll(repo)
+
+	testutil.WriteFiles(t, repo, map[string]string{
+		"legacy/build.xml":              "<project/>",
+		"legacy/src/acme/Legacy.java":   "package acme;",
+		"service/.java-version":         "17",
+		"service/build.gradle":          "",
+		"service/src/acme/Service.java": "package acme;",
+	})
+
+	// Installed Java versions aren't installed again.
+	for _, identifier := range []string{"8.0.265", "17.0.9-tem"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(repo, "sdkman", "candidates", "java", identifier), 0755))
+	}
+
+	projects, err := project.FindProjects(repo, true)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+
+	set := flag.NewFlagSet("analyze", 0)
+	set.String(sdkman.FlagJavaPath, "java", "")
+	set.String(sdkman.FlagJavaVersion, "8", "")
+	set.String(sdkman.FlagJava8Version, "8.0.265", "")
+	set.String(sdkman.FlagJava17Version, "17.0.9-tem", "")
+	set.String(sdkman.FlagSdkmanDir, filepath.Join(repo, "sdkman"), "")
+	c := cli.NewContext(nil, set, nil)
+
+	require.NoError(t, selectToolchains(c, projects))
+
+	got := make(map[string]string)
+	for _, p := range projects {
+		got[filepath.Base(p.Path)] = p.JavaHome()
+	}
+	want := map[string]string{
+		"legacy":  filepath.Join(repo, "sdkman", "candidates", "java", "8.0.265"),
+		"service": filepath.Join(repo, "sdkman", "candidates", "java", "17.0.9-tem"),
+	}
+	require.Equal(t, want, got)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"syscall"
 
@@ -79,13 +80,26 @@ func RunCmdWithTextErrorDetection(cmd *exec.Cmd, c *cli.Context, errorText strin
 	return nil
 }
 
-// SetupCmdNoStd sets up a command's directory and environment for execution.
-func SetupCmdNoStd(projectPath string, cmd *exec.Cmd) *exec.Cmd {
+// SetupCmdNoStd sets up a command's directory and environment for execution. The given environment variables
+// override the ones of the analyzer.
+func SetupCmdNoStd(projectPath string, cmd *exec.Cmd, env ...string) *exec.Cmd {
 	cmd.Dir = projectPath
-	cmd.Env = os.Environ()
+	cmd.Env = append(os.Environ(), env...)
 	return cmd
 }
 
+// JavaEnv returns the environment variables selecting the JDK of the given home directory, or nil if it's empty.
+func JavaEnv(javaHome string) []string {
+	if javaHome == "" {
+		return nil
+	}
+
+	return []string{
+		"JAVA_HOME=" + javaHome,
+		"PATH=" + filepath.Join(javaHome, "bin") + string(os.PathListSeparator) + os.Getenv("PATH"),
+	}
+}
+
 // WithWarning runs the function passed as argument and prints a warning if it returns an error
 func WithWarning(warning string, fun func() error) {
 	err := fun()
@@ -93,3 +107,26 @@ func WithWarning(warning string, fun func() error) {
 		log.Warnf("%s (%s)\n", warning, err.Error())
 	}
 }
+
+// SplitList returns the non empty elements of a comma separated list, without spaces.
+func SplitList(list string) []string {
+	var elements []string
+	for _, element := range strings.Split(list, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+
+	return elements
+}
+
+// Contains returns true if the value is one of the values.
+func Contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
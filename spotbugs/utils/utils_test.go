@@ -3,6 +3,7 @@ package utils
 import (
 	"os"
 	"os/exec"
+	"reflect"
 	"testing"
 
 	"github.com/urfave/cli"
@@ -62,3 +63,30 @@ func TestRunCmdWithTextErrorDetection(t *testing.T) {
 		})
 	}
 }
+
+func TestSetupCmdNoStd_JavaEnv(t *testing.T) {
+	cmd := SetupCmdNoStd("/tmp", exec.Command("sh", "-c", "printf '%s %s' \"$JAVA_HOME\" \"${PATH%%:*}\""), JavaEnv("/opt/jdk-17")...)
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(output), "/opt/jdk-17 /opt/jdk-17/bin"; got != want {
+		t.Errorf("Wrong Java environment. Expected %q but got %q", want, got)
+	}
+
+	if env := JavaEnv(""); env != nil {
+		t.Errorf("Expected no Java environment but got %v", env)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	want := []string{"out/classes", "lib/*.jar"}
+	if got := SplitList(" out/classes,, lib/*.jar ,"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wrong result. Expected:\n%#v\nbut got:\n%#v", want, got)
+	}
+
+	if got := SplitList(" , "); got != nil {
+		t.Errorf("Wrong result. Expected nil but got:\n%#v", got)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+const (
+	fileClasspath = "classpath.txt"
+	fileExclude   = "exclude.xml"
+	fileInclude   = "include.xml"
+	fileJarsList  = "jars.list"
+	fileLog       = "SpotBugs.log"
+	fileOutput    = "SpotBugs.xml"
+)
+
+// workspace is a temporary directory holding the files used and produced while analyzing a single project or archive:
+// the jar list, the filters, the SpotBugs XML report and the SpotBugs logs.
+type workspace struct {
+	Path string
+	keep bool
+}
+
+// newWorkspace creates a new workspace for the project or archive at the given path, in the directory set by the
+// work directory flag or in the default directory for temporary files.
+func newWorkspace(c *cli.Context, path string) (*workspace, error) {
+	root := c.String(flagWorkDir)
+	if root != "" {
+		if err := os.MkdirAll(root, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	wsPath, err := ioutil.TempDir(root, fmt.Sprintf("spotbugs-%s-", filepath.Base(path)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &workspace{Path: wsPath, keep: c.Bool(flagKeepArtifacts)}, nil
+}
+
+// Classpath returns the path of the file receiving the dependency classpath resolved by the build tool.
+func (w *workspace) Classpath() string {
+	return filepath.Join(w.Path, fileClasspath)
+}
+
+// IncludeFilter returns the path of the filter selecting the reported bugs.
+func (w *workspace) IncludeFilter() string {
+	return filepath.Join(w.Path, fileInclude)
+}
+
+// ExcludeFilter returns the path of the filter selecting the bugs that aren't reported.
+func (w *workspace) ExcludeFilter() string {
+	return filepath.Join(w.Path, fileExclude)
+}
+
+// JarsList returns the path of the file listing the jars of the auxiliary classpath.
+func (w *workspace) JarsList() string {
+	return filepath.Join(w.Path, fileJarsList)
+}
+
+// Output returns the path of the SpotBugs XML report.
+func (w *workspace) Output() string {
+	return filepath.Join(w.Path, fileOutput)
+}
+
+// Log returns the path of the file receiving the SpotBugs logs.
+func (w *workspace) Log() string {
+	return filepath.Join(w.Path, fileLog)
+}
+
+// Close removes the workspace, unless artifacts must be kept.
+func (w *workspace) Close() error {
+	if w.keep {
+		log.Infof("Keeping SpotBugs artifacts in %s\n", w.Path)
+		return nil
+	}
+
+	return os.RemoveAll(w.Path)
+}
+
+// checkFreshReport returns an error if the report at the given path is missing, empty or older than the given time,
+// which means it wasn't produced by the last SpotBugs run.
+func checkFreshReport(path string, since time.Time) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("SpotBugs didn't produce a report: %v", err)
+	}
+
+	if info.Size() == 0 {
+		return fmt.Errorf("SpotBugs produced an empty report %s", path)
+	}
+
+	// File systems may only record modification times with a one second precision.
+	if info.ModTime().Before(since.Truncate(time.Second)) {
+		return fmt.Errorf("report %s is older than the SpotBugs run", path)
+	}
+
+	return nil
+}
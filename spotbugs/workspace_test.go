@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestWorkspace(t *testing.T) {
+	root, err := ioutil.TempDir("", "test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	tests := []struct {
+		name     string
+		keep     bool
+		wantKept bool
+	}{
+		{name: "Removed", keep: false, wantKept: false},
+		{name: "Kept", keep: true, wantKept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := flag.NewFlagSet("workspace", 0)
+			set.String(flagWorkDir, filepath.Join(root, "work"), "")
+			set.Bool(flagKeepArtifacts, tt.keep, "")
+			c := cli.NewContext(nil, set, nil)
+
+			ws, err := newWorkspace(c, "/app/my-project")
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(root, "work"), filepath.Dir(ws.Path))
+			require.Contains(t, filepath.Base(ws.Path), "my-project")
+			require.Equal(t, filepath.Join(ws.Path, fileOutput), ws.Output())
+
+			require.NoError(t, ws.Close())
+			_, err = os.Stat(ws.Path)
+			require.Equal(t, tt.wantKept, err == nil)
+		})
+	}
+}
+
+func TestCheckFreshReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	report := filepath.Join(dir, fileOutput)
+	empty := filepath.Join(dir, "empty.xml")
+	stale := filepath.Join(dir, "stale.xml")
+	require.NoError(t, ioutil.WriteFile(report, []byte("<BugCollection/>"), 0644))
+	require.NoError(t, ioutil.WriteFile(empty, nil, 0644))
+	require.NoError(t, ioutil.WriteFile(stale, []byte("<BugCollection/>"), 0644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(stale, old, old))
+
+	since := time.Now().Add(-time.Minute)
+
+	require.NoError(t, checkFreshReport(report, since))
+	require.Error(t, checkFreshReport(filepath.Join(dir, "missing.xml"), since))
+	require.Error(t, checkFreshReport(empty, since))
+	require.Error(t, checkFreshReport(stale, since))
+}